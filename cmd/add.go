@@ -39,16 +39,41 @@ import (
 * No amount given
 	- Do nothing, but log or return error
 
+* Worked given instead of amount:
+	- Amount is calculated as worked minus expected work from the customers schedule
+	- Giving both amount and worked is an error
+
+* Append given:
+	- Add a new entry, even if there are other entries for the same date
+	- Not valid together with worked, as the expected work would be subtracted twice
+
+* ID given:
+	- Edit the entry with that ID, changing only the fields given
+
 */
 
 func entryPointAdd(c *cli.Context) error {
 	log.Debug().Msg("In entryPointAdd")
 
 	fileName := c.String("file")
-	customerName := c.String("customer")
-	date := c.Timestamp("date")
-	amount := c.Duration("amount")
+	customerName := customerFlag(c)
+	comment := c.String("comment")
 	overwrite := c.Bool("overwrite")
+	appendEntry := c.Bool("append")
+	id := c.String("id")
+
+	date, err := dateFlag(c, "date")
+	if err != nil {
+		return err
+	}
+	amount, err := durationFlag(c, "amount")
+	if err != nil {
+		return err
+	}
+	worked, err := durationFlag(c, "worked")
+	if err != nil {
+		return err
+	}
 
 	fmtDate := func(t *time.Time) string {
 		if t == nil {
@@ -62,9 +87,20 @@ func entryPointAdd(c *cli.Context) error {
 		Str("CustomerName", customerName).
 		Str("Date", fmtDate(date)).
 		Dur("Amount", amount).
+		Dur("Worked", worked).
+		Str("Comment", comment).
 		Bool("Overwrite", overwrite).
+		Bool("Append", appendEntry).
+		Str("ID", id).
 		Send()
 
+	if appendEntry && (overwrite || id != "" || c.IsSet("worked")) {
+		return fmt.Errorf("%w: append with overwrite, id or worked", ErrInvalidOptionCombination)
+	}
+	if c.IsSet("amount") && c.IsSet("worked") {
+		return fmt.Errorf("%w: amount and worked", ErrInvalidOptionCombination)
+	}
+
 	db, err := openDB(fileName)
 	if err != nil {
 		if db == nil {
@@ -73,18 +109,64 @@ func entryPointAdd(c *cli.Context) error {
 		log.Error().Err(err).Send()
 	}
 
-	if date == nil && customerName != "" {
+	// Without an ID, an amount or worked time is for today unless another date is given.
+	// When editing, the entry keeps its date.
+	if date == nil && id == "" && (c.IsSet("amount") || c.IsSet("worked")) {
+		today := flex.DateOf(time.Now())
+		date = &today
+	}
+
+	if id != "" {
+		customer, existing, err := db.FindEntry(customerName, id)
+		if err != nil {
+			return err
+		}
+		entry := *existing
+		if date != nil {
+			entry.Date = *date
+		}
+		if c.IsSet("comment") {
+			entry.Comment = comment
+		}
+		if c.IsSet("amount") {
+			entry.Amount = amount
+			entry.Worked = 0
+		} else if c.IsSet("worked") {
+			entry.Worked = worked
+			entry.Amount = worked - db.ExpectedWork(customer, entry.Date)
+		} else if entry.Worked != 0 && !entry.Date.Equal(existing.Date) {
+			// the flex of worked time depends on what is expected on the new date
+			entry.Amount = entry.Worked - db.ExpectedWork(customer, entry.Date)
+		}
+		customer.SetEntry(entry, true)
+	} else if date == nil && customerName != "" {
 		_, err := db.AddCustomer(customerName)
 		if err != nil {
 			return err
 		}
+	} else if c.IsSet("worked") {
+		entry, err := db.SetWorkedForCustomer(
+			customerName,
+			flex.Entry{Date: *date, Worked: worked, Comment: comment},
+			overwrite,
+		)
+		if err != nil {
+			return err
+		}
+		log.Debug().Dur("amount", entry.Amount).Msg("Calculated flex from worked time")
 	} else {
 		if amount == time.Duration(0) {
 			return fmt.Errorf("refusing to add entry with 0 flex amount")
 		}
-		err = db.SetFlexForCustomer(customerName, *date, amount, overwrite)
-		if err != nil {
-			return err
+		entry := flex.Entry{Date: *date, Amount: amount, Comment: comment}
+		if appendEntry {
+			entry := db.AddEntryForCustomer(customerName, entry)
+			log.Debug().Str("id", entry.ID).Msg("Appended entry")
+		} else {
+			err = db.SetEntryForCustomer(customerName, entry, overwrite)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
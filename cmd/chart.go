@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// minChartBarWidth is the least number of columns used for bars, however narrow the terminal
+const minChartBarWidth = 10
+
+// chartRunes are the characters charts are drawn with
+type chartRunes struct {
+	bar   rune
+	axis  rune
+	spark []rune // from lowest to highest
+}
+
+var (
+	unicodeChartRunes = chartRunes{bar: '█', axis: '│', spark: []rune("▁▂▃▄▅▆▇█")}
+	asciiChartRunes   = chartRunes{bar: '#', axis: '|', spark: []rune("_.-~=+*#")}
+)
+
+func entryPointChart(c *cli.Context) error {
+	log.Debug().Msg("In entryPointChart")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	all := c.Bool("all")
+	customerSort := stringFlag(c, "customer-sort", config.CustomerSort)
+	groupBy := c.String("group-by")
+	grep := c.String("grep")
+	width := c.Int("width")
+	ascii := c.Bool("ascii")
+
+	log.Debug().
+		Str("FileName", fileName).
+		Str("CustomerName", customerName).
+		Bool("All", all).
+		Str("GroupBy", groupBy).
+		Int("Width", width).
+		Bool("ASCII", ascii).
+		Send()
+
+	period, err := flex.ParsePeriod(groupBy)
+	if err != nil {
+		return err
+	}
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+
+	// Unicode and the terminal size are only used when drawing to a terminal,
+	// as the output may otherwise end up somewhere that can't show it
+	runes := unicodeChartRunes
+	if ascii || !isTerminal(os.Stdout) {
+		runes = asciiChartRunes
+	}
+	if width <= 0 {
+		width = terminalWidth(os.Stdout)
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	var customer *flex.Customer
+	if customerName != "" {
+		customer, err = db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+	}
+	customerSortValue := flex.CustomerNoSort
+	if value, ok := customerSortOrder[customerSort]; ok {
+		customerSortValue = value
+	}
+	customers := selectCustomers(db, customer, all, customerSortValue)
+	if customers == nil {
+		return fmt.Errorf("%w: either customer or all is needed", ErrInvalidOptionCombination)
+	}
+
+	var commentExpr *regexp.Regexp
+	if grep != "" {
+		commentExpr, err = regexp.Compile(grep)
+		if err != nil {
+			return err
+		}
+	}
+
+	listings, err := selectListings(db, customers, customer != nil, nil, from, to, commentExpr)
+	if err != nil {
+		return err
+	}
+
+	builder := strings.Builder{}
+	for idx, listing := range listings {
+		if idx > 0 {
+			builder.WriteString("\n")
+		}
+		report := newReportCustomer(listing, period, from, true)
+		writeChart(&builder, report, period, width, runes)
+	}
+	fmt.Print(builder.String())
+
+	return nil
+}
+
+// writeChart draws a bar per period and a sparkline of the running balance for one customer
+func writeChart(writer io.Writer, report reportCustomer, period flex.Period, width int, runes chartRunes) {
+	fmt.Fprintf(writer, "%s: %s (by %s)\n", report.Name, chartDuration(report.Total), period)
+	if len(report.Periods) == 0 {
+		return
+	}
+
+	labelWidth := len("balance")
+	valueWidth := 0
+	for _, total := range report.Periods {
+		labelWidth = maxInt(labelWidth, len(total.Label()))
+		valueWidth = maxInt(valueWidth, len(chartDuration(total.Amount)))
+	}
+	// label, space, bars with axis, space, value
+	barWidth := maxInt(width-labelWidth-valueWidth-3, minChartBarWidth)
+
+	writeBars(writer, report.Periods, labelWidth, valueWidth, barWidth, runes)
+
+	balances := make([]float64, 0, len(report.Periods))
+	for _, total := range report.Periods {
+		balances = append(balances, float64(total.Balance))
+	}
+	fmt.Fprintf(
+		writer,
+		"%-*s %s\n",
+		labelWidth,
+		"balance",
+		sparkline(balances, width-labelWidth-1, runes.spark),
+	)
+
+	lowest, highest := report.Periods[0], report.Periods[0]
+	for _, total := range report.Periods {
+		if total.Balance < lowest.Balance {
+			lowest = total
+		}
+		if total.Balance > highest.Balance {
+			highest = total
+		}
+	}
+	fmt.Fprintf(
+		writer,
+		"%-*s lowest %s in %s, highest %s in %s, now %s\n",
+		labelWidth,
+		"",
+		chartDuration(lowest.Balance),
+		lowest.Label(),
+		chartDuration(highest.Balance),
+		highest.Label(),
+		chartDuration(report.Balance),
+	)
+}
+
+// writeBars draws a horizontal bar per period, with negative amounts to the left of the axis
+// and positive to the right. The space for each side is in proportion to the largest amount on that side.
+func writeBars(writer io.Writer, totals flex.PeriodTotals, labelWidth, valueWidth, barWidth int, runes chartRunes) {
+	var maxPositive, maxNegative float64
+	for _, total := range totals {
+		amount := float64(total.Amount)
+		maxPositive = math.Max(maxPositive, amount)
+		maxNegative = math.Max(maxNegative, -amount)
+	}
+
+	columns := barWidth - 1 // one for the axis
+	negativeColumns := 0
+	if maxNegative > 0 {
+		negativeColumns = maxInt(int(math.Round(float64(columns)*maxNegative/(maxPositive+maxNegative))), 1)
+	}
+	positiveColumns := columns - negativeColumns
+
+	bar := func(amount, max float64, columns int) int {
+		if amount <= 0 || max <= 0 {
+			return 0
+		}
+		// Anything but zero gets at least one column, so it's visible
+		return maxInt(int(math.Round(amount/max*float64(columns))), 1)
+	}
+
+	for _, total := range totals {
+		amount := float64(total.Amount)
+		negative := bar(-amount, maxNegative, negativeColumns)
+		positive := bar(amount, maxPositive, positiveColumns)
+		fmt.Fprintf(
+			writer,
+			"%-*s %s%s%c%s%s %*s\n",
+			labelWidth,
+			total.Label(),
+			strings.Repeat(" ", negativeColumns-negative),
+			strings.Repeat(string(runes.bar), negative),
+			runes.axis,
+			strings.Repeat(string(runes.bar), positive),
+			strings.Repeat(" ", positiveColumns-positive),
+			valueWidth,
+			chartDuration(total.Amount),
+		)
+	}
+}
+
+// sparkline draws the values as a line of characters from levels, lowest to highest.
+// If there are more values than the given width, the last value within each column is used.
+func sparkline(values []float64, width int, levels []rune) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		sampled := make([]float64, width)
+		for idx := range sampled {
+			sampled[idx] = values[(idx+1)*len(values)/width-1]
+		}
+		values = sampled
+	}
+
+	lowest, highest := values[0], values[0]
+	for _, value := range values {
+		lowest = math.Min(lowest, value)
+		highest = math.Max(highest, value)
+	}
+
+	line := make([]rune, 0, len(values))
+	for _, value := range values {
+		level := len(levels) / 2
+		if highest > lowest {
+			level = int(math.Round((value - lowest) / (highest - lowest) * float64(len(levels)-1)))
+		}
+		line = append(line, levels[level])
+	}
+	return string(line)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// chartDuration formats amounts in charts, where the compact clock format is used
+// unless another format than the default is chosen with --duration-format
+func chartDuration(d time.Duration) string {
+	if durationFormat == flex.DurationFormatGo {
+		return formatClock(d)
+	}
+	return formatDuration(d)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointComment(c *cli.Context) error {
+	log.Debug().Msg("In entryPointComment")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	appendText := c.Bool("append")
+	id := c.String("id")
+	text := strings.Join(c.Args().Slice(), " ")
+
+	date, err := dateFlag(c, "date")
+	if err != nil {
+		return err
+	}
+
+	if (date == nil) == (id == "") {
+		return fmt.Errorf("%w: give either date or id", ErrInvalidOptionCombination)
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	var customer *flex.Customer
+	var entry *flex.Entry
+	if id != "" {
+		customer, entry, err = db.FindEntry(customerName, id)
+		if err != nil {
+			return err
+		}
+	} else {
+		if customerName == "" {
+			customer = db.GetDefaultCustomer()
+		} else {
+			customer, err = db.GetCustomer(customerName)
+			if err != nil {
+				return err
+			}
+		}
+		entries, err := customer.GetEntries(*date)
+		if err != nil {
+			return err
+		}
+		if entries.Len() > 1 {
+			return fmt.Errorf("%w: %s, use id", flex.ErrAmbiguousDate, date.Format(flex.ShortDateFormat))
+		}
+		entry = entries[0]
+	}
+
+	if appendText {
+		entry.AppendComment(text)
+	} else {
+		entry.Comment = text
+	}
+
+	log.Info().
+		Str("customer_name", customer.Name).
+		Str("date", entry.Date.Format(flex.ShortDateFormat)).
+		Str("id", entry.ID).
+		Str("comment", entry.Comment).
+		Msg("Set comment for entry")
+
+	return saveDB(db)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// config holds the defaults from the config file, loaded by loadConfig in the Before hook
+var config = &flex.Config{}
+
+// standardWorkday is the configured workday length, given to each opened DB
+var standardWorkday time.Duration
+
+func defaultConfigFileName() string {
+	fileName, err := flex.DefaultConfigFileName()
+	if err != nil {
+		log.Debug().Err(err).Msg("No default config file")
+		return ""
+	}
+	return fileName
+}
+
+// loadConfig loads the config file given with --config, and applies the defaults for global flags
+// not given on the command line or in the environment
+func loadConfig(c *cli.Context) error {
+	loaded, err := flex.LoadConfig(c.String("config"))
+	if err != nil {
+		return err
+	}
+	config = loaded
+	log.Debug().Str("config", config.FileName).Msg("Loaded config")
+
+	if !c.IsSet("file") && config.File != "" {
+		if err := c.Set("file", config.File); err != nil {
+			return err
+		}
+	}
+	if !c.IsSet("backups") && config.Backups != nil {
+		if err := c.Set("backups", strconv.Itoa(*config.Backups)); err != nil {
+			return err
+		}
+	}
+	standardWorkday, err = config.WorkdayLength()
+	return err
+}
+
+// stringFlag returns the value of the named flag if given, otherwise fallback from the config if not empty,
+// otherwise the default value of the flag
+func stringFlag(c *cli.Context, name, fallback string) string {
+	if c.IsSet(name) || fallback == "" {
+		return c.String(name)
+	}
+	return fallback
+}
+
+// customerFlag returns the customer given with --customer, otherwise the configured default customer.
+// The default customer is not used with --all or --default, which are instead of a customer,
+// nor with --id, as entry IDs are found among all customers.
+func customerFlag(c *cli.Context) string {
+	if c.Bool("all") || c.Bool("default") || c.String("id") != "" {
+		return c.String("customer")
+	}
+	return stringFlag(c, "customer", config.Customer)
+}
+
+// decimalSeparator returns text with decimal points replaced according to the configured locale
+func decimalSeparator(text string) string {
+	if config.DecimalComma() {
+		return strings.ReplaceAll(text, ".", ",")
+	}
+	return text
+}
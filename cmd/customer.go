@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointCustomerRename(c *cli.Context) error {
+	log.Debug().Msg("In entryPointCustomerRename")
+
+	fileName := c.String("file")
+	if c.NArg() != 2 {
+		return fmt.Errorf("%w: give the old and the new name", ErrInvalidOptionCombination)
+	}
+	oldName := c.Args().Get(0)
+	newName := c.Args().Get(1)
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer, err := db.RenameCustomer(oldName, newName)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("from", oldName).Str("to", customer.Name).Msg("Renamed customer")
+
+	return saveDB(db)
+}
+
+func entryPointCustomerMerge(c *cli.Context) error {
+	log.Debug().Msg("In entryPointCustomerMerge")
+
+	fileName := c.String("file")
+	dryRun := c.Bool("dry-run")
+	if c.NArg() != 2 {
+		return fmt.Errorf("%w: give the customer to merge into, and the one to merge from", ErrInvalidOptionCombination)
+	}
+	leftName := c.Args().Get(0)
+	rightName := c.Args().Get(1)
+
+	policy, err := flex.ParseMergePolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	if dryRun {
+		left, err := db.GetCustomer(leftName)
+		if err != nil {
+			return err
+		}
+		right, err := db.GetCustomer(rightName)
+		if err != nil {
+			return err
+		}
+		fmt.Printf(
+			"Would merge %s from %s into %s\n",
+			countEntries(right.Entries.Len()),
+			right.Name,
+			left.Name,
+		)
+		for _, date := range flex.ConflictingDates(left, right) {
+			fmt.Printf("\t* %s: entries for both (%s)\n", date.Format(flex.ShortDateFormat), policy)
+		}
+		return nil
+	}
+
+	customer, err := db.MergeCustomers(leftName, rightName, policy)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("into", customer.Name).Str("from", rightName).Msg("Merged customers")
+
+	return saveDB(db)
+}
+
+func entryPointCustomerInfo(c *cli.Context) error {
+	log.Debug().Msg("In entryPointCustomerInfo")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer := db.GetDefaultCustomer()
+	if customerName != "" {
+		customer, err = db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	builder := strings.Builder{}
+	writeCustomerInfo(&builder, db, customer)
+	fmt.Print(builder.String())
+
+	return nil
+}
+
+func writeCustomerInfo(writer io.Writer, db *flex.DB, customer *flex.Customer) {
+	status := "active"
+	if customer.Archived {
+		status = "archived"
+	}
+	fmt.Fprintf(writer, "%s (%s)\n", customer.Name, status)
+	if customer.DisplayName != "" {
+		fmt.Fprintf(writer, "\tDisplay name: %s\n", customer.DisplayName)
+	}
+	if customer.StartDate != nil {
+		fmt.Fprintf(writer, "\tStart date  : %s\n", customer.StartDate.Format(flex.ShortDateFormat))
+	}
+	fmt.Fprintf(writer, "\tEntries     : %d\n", customer.Entries.Len())
+	if first, err := customer.Entries.FirstDate(); err == nil {
+		last, _ := customer.Entries.LastDate()
+		fmt.Fprintf(
+			writer,
+			"\tDates       : %s - %s\n",
+			first.Format(flex.ShortDateFormat),
+			last.Format(flex.ShortDateFormat),
+		)
+	}
+	fmt.Fprintf(writer, "\tFlex        : %s\n", formatDuration(customer.GetTotalFlex()))
+	scheduleSource := "own"
+	if customer.Schedule == nil {
+		scheduleSource = "default"
+	}
+	fmt.Fprintf(writer, "\tSchedule    : %s (%s)\n", db.ScheduleFor(customer).Format(formatDuration), scheduleSource)
+	if customer.HasSession() {
+		fmt.Fprintf(
+			writer,
+			"\tSession     : running since %s\n",
+			customer.Session.Start.Format(flex.ShortDateFormat+" "+flex.ClockFormat),
+		)
+	}
+	if customer.Notes != "" {
+		fmt.Fprintf(writer, "\tNotes       : %s\n", strings.ReplaceAll(customer.Notes, "\n", "\n\t              "))
+	}
+}
+
+func entryPointCustomerSet(c *cli.Context) error {
+	log.Debug().Msg("In entryPointCustomerSet")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+
+	if customerName == "" {
+		return fmt.Errorf("%w: customer is required", ErrInvalidOptionCombination)
+	}
+
+	// An empty start date clears it
+	var startDate *time.Time
+	if c.String("start-date") != "" {
+		date, err := dateFlag(c, "start-date")
+		if err != nil {
+			return err
+		}
+		startDate = date
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer, err := db.GetCustomer(customerName)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if c.IsSet("display-name") {
+		customer.DisplayName = strings.TrimSpace(c.String("display-name"))
+		changed = true
+	}
+	if c.IsSet("notes") {
+		customer.Notes = c.String("notes")
+		changed = true
+	}
+	if c.IsSet("archived") {
+		customer.Archived = c.Bool("archived")
+		changed = true
+	}
+	if c.IsSet("start-date") {
+		customer.StartDate = startDate
+		changed = true
+	}
+	if !changed {
+		return fmt.Errorf("%w: nothing to set", ErrInvalidOptionCombination)
+	}
+
+	return saveDB(db)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/urfave/cli/v2"
+)
+
+// periodFlag is the --period flag for commands taking a date range with --from and --to
+func periodFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "period",
+		Aliases: []string{"p"},
+		Usage:   fmt.Sprintf("Date `range`, instead of from and to (%s)", flex.PeriodInputHelp),
+	}
+}
+
+func parseDateRangeFlag(c *cli.Context, name string) (*flex.DateRange, error) {
+	if !c.IsSet(name) {
+		return nil, nil
+	}
+	dateRange, err := flex.ParseDateRange(c.String(name), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", name, err)
+	}
+	return &dateRange, nil
+}
+
+// dateFlag returns the date given with the named flag, or nil if not given.
+// Input naming a longer period, like a month, gives its first date.
+func dateFlag(c *cli.Context, name string) (*time.Time, error) {
+	dateRange, err := parseDateRangeFlag(c, name)
+	if dateRange == nil {
+		return nil, err
+	}
+	return &dateRange.From, nil
+}
+
+// rangeFlags returns the dates given with --from and --to, or the range given with --period.
+// Either date is nil if not given. A longer period given with --from starts at its first date,
+// and given with --to ends at its last, so that "--from 2022-03 --to 2022-04" is all of March and April.
+func rangeFlags(c *cli.Context) (*time.Time, *time.Time, error) {
+	if c.IsSet("period") {
+		if c.IsSet("from") || c.IsSet("to") {
+			return nil, nil, fmt.Errorf("%w: period with from or to", ErrInvalidOptionCombination)
+		}
+		dateRange, err := flex.ParsePeriodRange(c.String("period"), time.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("--period: %w", err)
+		}
+		return &dateRange.From, &dateRange.To, nil
+	}
+	return spanFlags(c, "from", "to", false)
+}
+
+// spanFlags returns the dates given with the flags named first and last, or nil for any not given.
+// A longer period given as first starts at its first date, and given as last ends at its last.
+// If whole is true and only first is given, a longer period gives its whole range,
+// so that e.g. "--date 2022-W30" is all of week 30.
+func spanFlags(c *cli.Context, first, last string, whole bool) (*time.Time, *time.Time, error) {
+	firstRange, err := parseDateRangeFlag(c, first)
+	if err != nil {
+		return nil, nil, err
+	}
+	lastRange, err := parseDateRangeFlag(c, last)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var from, to *time.Time
+	if firstRange != nil {
+		from = &firstRange.From
+		if whole && lastRange == nil && !firstRange.To.Equal(firstRange.From) {
+			to = &firstRange.To
+		}
+	}
+	if lastRange != nil {
+		to = &lastRange.To
+	}
+	return from, to, nil
+}
@@ -3,11 +3,39 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/oddlid/flextime/flex"
 )
 
+// backupCount is how many backup copies of the DB file saveDB keeps
+var backupCount = flex.DefaultBackupCount
+
+// openDB reads the DB from the given file, see readDB, and applies the configured workday length
 func openDB(fileName string) (*flex.DB, error) {
+	return configureDB(readDB(fileName, flex.DecodeDB))
+}
+
+// openDBLenient works like openDB, but reads past fields that are not part of the DB, see flex.DecodeDBLenient.
+// A DB from an older schema version is returned as it is, along with flex.ErrOutdatedSchemaVersion.
+func openDBLenient(fileName string) (*flex.DB, error) {
+	return configureDB(readDB(fileName, func(reader io.Reader) (*flex.DB, error) {
+		db, _, err := flex.DecodeDBLenient(reader)
+		return db, err
+	}))
+}
+
+// configureDB applies the configured workday length to the DB, if there is one
+func configureDB(db *flex.DB, err error) (*flex.DB, error) {
+	if db != nil {
+		db.Workday = standardWorkday
+		workdayLength = db.ScheduleFor(nil).Workday()
+	}
+	return db, err
+}
+
+func readDB(fileName string, decode func(io.Reader) (*flex.DB, error)) (*flex.DB, error) {
 	if fileName == "" {
 		db := flex.NewDB()
 		db.FileName = "-"
@@ -21,11 +49,21 @@ func openDB(fileName string) (*flex.DB, error) {
 		return db, err
 	}
 
-	db, err := flex.DecodeDB(file)
+	db, err := decode(file)
 	if err != nil {
-		if errors.Is(err, flex.ErrEmptyDB) {
+		switch {
+		case errors.Is(err, flex.ErrEmptyDB):
 			db = flex.NewDB()
-		} else {
+		case errors.Is(err, flex.ErrUnknownField):
+			return nil, fmt.Errorf("%w (doctor --fix drops them)", err)
+		case errors.Is(err, flex.ErrOutdatedSchemaVersion) && db != nil:
+			// only read leniently, see openDBLenient
+			db.FileName = fileName
+			if fileName != "-" {
+				file.Close()
+			}
+			return db, err
+		default:
 			return nil, err
 		}
 	}
@@ -38,21 +76,14 @@ func openDB(fileName string) (*flex.DB, error) {
 }
 
 func saveDB(db *flex.DB) error {
-	if db == nil {
-		return fmt.Errorf("refusing to save nil DB")
-	}
-	file, err := flex.GetFileOrStdoutForWriting(db.FileName)
-	if err != nil {
-		return err
-	}
-	err = flex.EncodeDB(db, file)
-	if err != nil {
-		return err
-	}
-	if db.FileName != "-" {
-		if err = file.Close(); err != nil {
-			return err
-		}
+	return flex.SaveDB(db, backupCount)
+}
+
+// reportWriter returns where to write what a command did or would do to the DB,
+// which is stdout unless the DB is saved there
+func reportWriter(db *flex.DB) io.Writer {
+	if db.FileName == "-" {
+		return os.Stderr
 	}
-	return nil
+	return os.Stdout
 }
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/oddlid/flextime/flex"
@@ -13,11 +18,20 @@ func entryPointDelete(c *cli.Context) error {
 	log.Debug().Msg("In entryPointDelete")
 
 	fileName := c.String("file")
-	customerName := c.String("customer")
+	customerName := customerFlag(c)
 	all := c.Bool("all")
-	date := c.Timestamp("date")
-	from := c.Timestamp("from")
-	to := c.Timestamp("to")
+	id := c.String("id")
+	dryRun := c.Bool("dry-run")
+	yes := c.Bool("yes")
+
+	date, err := dateFlag(c, "date")
+	if err != nil {
+		return err
+	}
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
 
 	db, err := openDB(fileName)
 	if err != nil {
@@ -29,27 +43,52 @@ func entryPointDelete(c *cli.Context) error {
 		}
 	}
 
+	// Deletions are done on a copy, so that we can tell what would be lost before anything is saved
+	working, err := db.Clone()
+	if err != nil {
+		return err
+	}
+
 	var customer *flex.Customer
 	if customerName != "" {
-		customer, err = db.GetCustomer(customerName)
+		customer, err = working.GetCustomer(customerName)
 		if err != nil {
 			return err
 		}
 	}
 
-	if err := dispatchDeleteAction(all, db, customer, date, from, to); err != nil {
+	if err := dispatchDeleteAction(all, working, customer, id, date, from, to); err != nil {
 		return err
 	}
 
-	err = saveDB(db)
+	plan := planDelete(db, working)
+	if dryRun {
+		plan.write(os.Stdout, db.Calendar)
+		return nil
+	}
+	if plan.needsConfirmation() && !yes {
+		plan.write(reportWriter(db), db.Calendar)
+		confirmed, err := confirm("Delete?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Nothing deleted")
+			return nil
+		}
+	}
+
+	err = saveDB(working)
 	if err != nil {
 		return err
 	}
 
+	plan.log()
+
 	return nil
 }
 
-func dispatchDeleteAction(all bool, db *flex.DB, customer *flex.Customer, date, from, to *time.Time) error {
+func dispatchDeleteAction(all bool, db *flex.DB, customer *flex.Customer, id string, date, from, to *time.Time) error {
 	fmtCustomer := func(c *flex.Customer) string {
 		if c == nil {
 			return "<nil>"
@@ -68,12 +107,22 @@ func dispatchDeleteAction(all bool, db *flex.DB, customer *flex.Customer, date,
 		log.Debug().
 			Bool("all", all).
 			Str("customer", fmtCustomer(customer)).
+			Str("id", id).
 			Str("date", fmtDate(date)).
 			Str("from", fmtDate(from)).
 			Str("to", fmtDate(to)).
 			Msg(msg)
 	}
 
+	if id != "" {
+		if all || date != nil || from != nil || to != nil {
+			localLog("Invalid option combination")
+			return ErrInvalidOptionCombination
+		}
+		localLog("delete specific entry by id")
+		return deleteEntryByID(db, customer, id)
+	}
+
 	switch all {
 	case true:
 		switch {
@@ -124,6 +173,31 @@ func deleteCustomer(db *flex.DB, customer *flex.Customer) error {
 	return nil
 }
 
+// deleteEntryByID deletes the entry with the given ID from the given customer,
+// or from whichever customer has it if customer is nil.
+func deleteEntryByID(db *flex.DB, customer *flex.Customer, id string) error {
+	if db == nil || db.IsEmpty() {
+		return flex.ErrEmptyDB
+	}
+	customerName := ""
+	if customer != nil {
+		customerName = customer.Name
+	}
+	customer, entry, err := db.FindEntry(customerName, id)
+	if err != nil {
+		return err
+	}
+	customer.Entries.DeleteByID(entry.ID)
+
+	log.Debug().
+		Str("customer_name", customer.Name).
+		Str("id", entry.ID).
+		Str("date", entry.Date.Format(flex.ShortDateFormat)).
+		Msg("Deleted entry with given id from customer")
+
+	return nil
+}
+
 func deleteSpecificDateFromCustomer(customer *flex.Customer, date time.Time) error {
 	if customer == nil {
 		return flex.ErrNilCustomer
@@ -135,7 +209,7 @@ func deleteSpecificDateFromCustomer(customer *flex.Customer, date time.Time) err
 		return fmt.Errorf("%w: %s", flex.ErrNoEntry, date.Format(flex.ShortDateFormat))
 	}
 
-	log.Info().
+	log.Debug().
 		Str("customer_name", customer.Name).
 		Str("date", date.Format(flex.ShortDateFormat)).
 		Msg("Deleted entry with given date from customer")
@@ -149,7 +223,7 @@ func deleteAllEntriesFromCustomer(customer *flex.Customer) error {
 	}
 	customer.Entries = make(flex.Entries, 0)
 
-	log.Info().
+	log.Debug().
 		Str("customer_name", customer.Name).
 		Msg("Deleted all entries from customer")
 
@@ -184,7 +258,7 @@ func deleteDateRangeFromCustomer(customer *flex.Customer, from, to *time.Time) e
 	entriesDeleted := customer.Entries.Len() - filteredEntries.Len()
 	customer.Entries = filteredEntries
 
-	log.Info().
+	log.Debug().
 		Str("customer_name", customer.Name).
 		Int("entries_deleted", entriesDeleted).
 		Msg("Deleted entries in date range from customer")
@@ -200,7 +274,7 @@ func deleteAllEntriesFromAllCustomers(db *flex.DB) error {
 		customer.Entries = make(flex.Entries, 0)
 	}
 
-	log.Info().Msg("Deleted all entries from all customers")
+	log.Debug().Msg("Deleted all entries from all customers")
 
 	return nil
 }
@@ -212,12 +286,12 @@ func deleteSpecificDateFromAllCustomers(db *flex.DB, date time.Time) error {
 
 	entriesDeleted := 0
 	for _, customer := range db.Customers {
-		if customer.Entries.DeleteByDate(date) {
-			entriesDeleted++
-		}
+		entriesBefore := customer.Entries.Len()
+		customer.Entries.DeleteByDate(date)
+		entriesDeleted += entriesBefore - customer.Entries.Len()
 	}
 
-	log.Info().
+	log.Debug().
 		Time("date", date).
 		Int("entries_deleted", entriesDeleted).
 		Msg("Deleted entries matching date from all customers")
@@ -259,9 +333,145 @@ func deleteDateRangeFromAllCustomers(db *flex.DB, from, to *time.Time) error {
 		customer.Entries = filteredEntries
 	}
 
-	log.Info().
+	log.Debug().
 		Int("entries_deleted", entriesDeleted).
 		Msg("Deleted entries matching date range from all customers")
 
 	return nil
 }
+
+// deletedCustomer is what a delete removes from a single customer
+type deletedCustomer struct {
+	name     string
+	entries  flex.Entries
+	customer bool // the customer itself is deleted, not just entries
+}
+
+// deletePlan lists what a delete removes, per customer
+type deletePlan []deletedCustomer
+
+// planDelete compares the DB before and after a delete, and returns what was removed
+func planDelete(before, after *flex.DB) deletePlan {
+	plan := make(deletePlan, 0)
+	for _, customer := range before.Customers {
+		remaining, err := after.GetCustomer(customer.Name)
+		if err != nil {
+			plan = append(plan, deletedCustomer{name: customer.Name, entries: customer.Entries, customer: true})
+			continue
+		}
+		removed := make(flex.Entries, 0)
+		for _, entry := range customer.Entries {
+			if remaining.Entries.IndexOfID(entry.ID) < 0 {
+				removed = append(removed, entry)
+			}
+		}
+		if removed.Len() > 0 {
+			plan = append(plan, deletedCustomer{name: customer.Name, entries: removed})
+		}
+	}
+	return plan
+}
+
+func (plan deletePlan) entryCount() int {
+	count := 0
+	for _, deleted := range plan {
+		count += deleted.entries.Len()
+	}
+	return count
+}
+
+func (plan deletePlan) flexTotal() time.Duration {
+	var total time.Duration
+	for _, deleted := range plan {
+		total += deleted.entries.GetTotalFlex()
+	}
+	return total
+}
+
+// needsConfirmation returns true if more than one entry or a whole customer would be deleted
+func (plan deletePlan) needsConfirmation() bool {
+	if plan.entryCount() > 1 {
+		return true
+	}
+	for _, deleted := range plan {
+		if deleted.customer {
+			return true
+		}
+	}
+	return false
+}
+
+func (plan deletePlan) write(writer io.Writer, calendar flex.Calendar) {
+	if len(plan) == 0 {
+		fmt.Fprintln(writer, "Nothing would be deleted")
+		return
+	}
+	for _, deleted := range plan {
+		what := "entries"
+		if deleted.customer {
+			what = "customer and entries"
+		}
+		fmt.Fprintf(
+			writer,
+			"%s: delete %s, %s, %s total\n",
+			deleted.name,
+			what,
+			countEntries(deleted.entries.Len()),
+			formatDuration(deleted.entries.GetTotalFlex()),
+		)
+		sorted := append(flex.Entries(nil), deleted.entries...)
+		sorted.Sort(flex.EntrySortByDateAscending)
+		for _, entry := range sorted {
+			writeEntry(writer, calendar, entry)
+		}
+	}
+	fmt.Fprintf(
+		writer,
+		"Total: %s from %d customer(s), %s flex\n",
+		countEntries(plan.entryCount()),
+		len(plan),
+		formatDuration(plan.flexTotal()),
+	)
+}
+
+func countEntries(count int) string {
+	if count == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", count)
+}
+
+func (plan deletePlan) log() {
+	for _, deleted := range plan {
+		msg := "Deleted entries from customer"
+		if deleted.customer {
+			msg = "Deleted customer"
+		}
+		log.Info().
+			Str("customer_name", deleted.name).
+			Int("entries_deleted", deleted.entries.Len()).
+			Dur("flex_deleted", deleted.entries.GetTotalFlex()).
+			Msg(msg)
+	}
+}
+
+// confirm asks the user on the terminal, and returns true if the answer is yes.
+// If stdin is not a terminal, or gives no answer, ErrNotConfirmed is returned, as there is nobody to ask.
+func confirm(question string) (bool, error) {
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("%w: stdin is not a terminal, use --yes", ErrNotConfirmed)
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		if answer == "" {
+			fmt.Fprintln(os.Stderr)
+			return false, fmt.Errorf("%w: no answer on stdin, use --yes", ErrNotConfirmed)
+		}
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
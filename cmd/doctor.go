@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointDoctor(c *cli.Context) error {
+	log.Debug().Msg("In entryPointDoctor")
+
+	fileName := c.String("file")
+	fixDuplicates := c.Bool("fix-duplicates")
+	fix := c.Bool("fix") || fixDuplicates
+
+	if fileName == "" {
+		return fmt.Errorf("%w: no file to check", ErrInvalidOptionCombination)
+	}
+
+	file, err := flex.GetFileOrStdinForReading(fileName)
+	if err != nil {
+		return err
+	}
+	// Unknown fields are read past, to report them along with everything else
+	db, unknown, err := flex.DecodeDBLenient(file)
+	if fileName != "-" {
+		file.Close()
+	}
+	if err != nil {
+		return err
+	}
+	db.FileName = fileName
+	findings := flex.UnknownFieldFindings(unknown)
+
+	if !fix {
+		findings = append(findings, db.Validate()...)
+		writeFindings(os.Stdout, fileName, findings)
+		return nil
+	}
+
+	// Unknown fields are dropped by saving
+	for idx := range findings {
+		findings[idx].Fixed = true
+	}
+	findings = append(findings, db.Fix(fixDuplicates)...)
+	fixed := 0
+	for _, finding := range findings {
+		if finding.Fixed {
+			fixed++
+		}
+	}
+	if fixed > 0 {
+		if err = saveDB(db); err != nil {
+			return err
+		}
+	}
+	writeFindings(reportWriter(db), fileName, findings)
+
+	return nil
+}
+
+func writeFindings(writer io.Writer, fileName string, findings []flex.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintf(writer, "%s: no problems found\n", fileName)
+		return
+	}
+	problems := "problems"
+	if len(findings) == 1 {
+		problems = "problem"
+	}
+	fmt.Fprintf(writer, "%s: %d %s found\n", fileName, len(findings), problems)
+	for _, finding := range findings {
+		status := ""
+		if finding.Fixed {
+			status = "fixed: "
+		}
+		fmt.Fprintf(writer, "\t%s%s\n", status, finding)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/urfave/cli/v2"
+)
+
+// durationFlag returns the amount of time given with the named flag, see flex.ParseFlexDuration,
+// or 0 if not given
+func durationFlag(c *cli.Context, name string) (time.Duration, error) {
+	if !c.IsSet(name) {
+		return 0, nil
+	}
+	duration, err := flex.ParseFlexDuration(c.String(name))
+	if err != nil {
+		return 0, fmt.Errorf("--%s: %w", name, err)
+	}
+	return duration, nil
+}
+
+// durationFormat is how amounts of time are shown in text output, set with --duration-format
+var durationFormat = flex.DurationFormatGo
+
+// workdayLength is the length of a workday for flex.DurationFormatWorkdays, from the schedule of the opened DB
+var workdayLength = flex.DefaultWorkdayLength
+
+// durationFormatFlag is the --duration-format flag for output commands, which overrides the global one,
+// so that it can be given after the command name too. See applyDurationFormat.
+func durationFormatFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "duration-format",
+		Aliases: []string{"D"},
+		Usage:   fmt.Sprintf("How amounts of time are shown (options: %s)", flex.DurationFormatOptions()),
+	}
+}
+
+// applyDurationFormat sets durationFormat from the --duration-format flag of the command, if given.
+// Otherwise the global flag, or the config file, is in effect.
+func applyDurationFormat(c *cli.Context) error {
+	if !c.IsSet("duration-format") {
+		return nil
+	}
+	format, err := flex.ParseDurationFormat(c.String("duration-format"))
+	if err != nil {
+		return err
+	}
+	durationFormat = format
+	return nil
+}
+
+// formatDuration formats d for text output, according to durationFormat and the configured locale
+func formatDuration(d time.Duration) string {
+	if durationFormat == flex.DurationFormatDecimal {
+		return decimalSeparator(durationFormat.Format(d, workdayLength))
+	}
+	return durationFormat.Format(d, workdayLength)
+}
@@ -4,4 +4,7 @@ import "errors"
 
 var (
 	ErrInvalidOptionCombination = errors.New("invalid option combination")
+	ErrNotConfirmed             = errors.New("not confirmed")
+	ErrInvalidOutputFormat      = errors.New("invalid output format")
+	ErrRejectedRows             = errors.New("rejected rows")
 )
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointExportTimeclock(c *cli.Context) error {
+	log.Debug().Msg("In entryPointExportTimeclock")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	all := c.Bool("all")
+
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+	start, err := time.Parse(flex.ClockFormat, c.String("start"))
+	if err != nil {
+		return err
+	}
+	options := flex.TimeclockOptions{
+		Account: c.String("account"),
+		Start:   time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		// the flex of dates with no time worked is only written in comments, for people to read
+		FormatDuration: formatDuration,
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer, err := exportCustomer(db, customerName, all)
+	if err != nil {
+		return err
+	}
+
+	listings, err := selectListings(
+		db,
+		selectCustomers(db, customer, all, flex.CustomerSortByNameAscending),
+		!all,
+		nil,
+		from,
+		to,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	for _, listing := range listings {
+		listing.entries.Sort(flex.EntrySortByDateAscending)
+		if err = db.WriteTimeclock(os.Stdout, listing.customer, listing.entries, options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func entryPointExportICS(c *cli.Context) error {
+	log.Debug().Msg("In entryPointExportICS")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	all := c.Bool("all")
+
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer, err := exportCustomer(db, customerName, all)
+	if err != nil {
+		return err
+	}
+
+	return flex.WriteICalendar(
+		os.Stdout,
+		selectCustomers(db, customer, all, flex.CustomerSortByNameAscending),
+		from,
+		to,
+		time.Now(),
+		icalAmountFormat(),
+	)
+}
+
+// icalAmountFormat returns how amounts are formatted in an iCalendar feed,
+// which is its own short format unless another format than the default is chosen with --duration-format
+func icalAmountFormat() func(time.Duration) string {
+	if durationFormat == flex.DurationFormatGo {
+		return nil
+	}
+	return formatDuration
+}
+
+// exportCustomer returns the customer to export entries for, which is the default if none is named,
+// or nil if all customers are to be exported
+func exportCustomer(db *flex.DB, customerName string, all bool) (*flex.Customer, error) {
+	switch {
+	case all:
+		if customerName != "" {
+			return nil, fmt.Errorf("%w: all and customer", ErrInvalidOptionCombination)
+		}
+		return nil, nil
+	case customerName == "":
+		return db.GetDefaultCustomer(), nil
+	default:
+		return db.GetCustomer(customerName)
+	}
+}
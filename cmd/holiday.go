@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// datesInRange returns each date from "from" to "to", inclusive.
+// If to is nil, only from is returned. A to before from is an error, rather than no dates.
+func datesInRange(from time.Time, to *time.Time) ([]time.Time, error) {
+	if to == nil {
+		return []time.Time{from}, nil
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf(
+			"%w: --to %s is before --date %s",
+			flex.ErrInvalidDate,
+			to.Format(flex.ShortDateFormat),
+			from.Format(flex.ShortDateFormat),
+		)
+	}
+	dates := make([]time.Time, 0)
+	for date := from; !date.After(*to); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+func entryPointHolidayAdd(c *cli.Context) error {
+	log.Debug().Msg("In entryPointHolidayAdd")
+
+	fileName := c.String("file")
+	date, to, err := spanFlags(c, "date", "to", true)
+	if err != nil {
+		return err
+	}
+	name := c.String("name")
+	overwrite := c.Bool("overwrite")
+
+	if date == nil {
+		return fmt.Errorf("%w: date is required", ErrInvalidOptionCombination)
+	}
+	dates, err := datesInRange(*date, to)
+	if err != nil {
+		return err
+	}
+
+	kind, err := flex.ParseDayKind(c.String("kind"))
+	if err != nil {
+		return err
+	}
+
+	var expected *time.Duration
+	if c.IsSet("hours") {
+		hours := c.Duration("hours")
+		expected = &hours
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	for _, day := range dates {
+		calendarDay := flex.CalendarDay{
+			Date:     day,
+			Kind:     kind,
+			Name:     name,
+			Expected: expected,
+		}
+		if !db.Calendar.Set(calendarDay, overwrite) {
+			return fmt.Errorf(
+				"failed to add %s on %s (overwrite: %t)",
+				kind,
+				day.Format(flex.ShortDateFormat),
+				overwrite,
+			)
+		}
+	}
+	db.Calendar.Sort()
+
+	return saveDB(db)
+}
+
+func entryPointHolidayList(c *cli.Context) error {
+	log.Debug().Msg("In entryPointHolidayList")
+
+	fileName := c.String("file")
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	calendar := make(flex.Calendar, 0, db.Calendar.Len())
+	for _, day := range db.Calendar {
+		if (from != nil && day.Date.Before(*from)) || (to != nil && day.Date.After(*to)) {
+			continue
+		}
+		calendar = append(calendar, day)
+	}
+	calendar.Sort()
+
+	for _, day := range calendar {
+		fmt.Printf("%s: %s\n", day.Date.Format(flex.ShortDateFormat), day.Format(formatDuration))
+	}
+
+	return nil
+}
+
+func entryPointHolidayRemove(c *cli.Context) error {
+	log.Debug().Msg("In entryPointHolidayRemove")
+
+	fileName := c.String("file")
+	date, to, err := spanFlags(c, "date", "to", true)
+	if err != nil {
+		return err
+	}
+
+	if date == nil {
+		return fmt.Errorf("%w: date is required", ErrInvalidOptionCombination)
+	}
+	dates, err := datesInRange(*date, to)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	daysDeleted := 0
+	for _, day := range dates {
+		if db.Calendar.Delete(day) {
+			daysDeleted++
+		}
+	}
+	if daysDeleted == 0 {
+		return fmt.Errorf("%w: %s", flex.ErrNoCalendarDay, date.Format(flex.ShortDateFormat))
+	}
+
+	log.Info().
+		Int("days_deleted", daysDeleted).
+		Msg("Deleted days from calendar")
+
+	return saveDB(db)
+}
+
+func entryPointHolidayImport(c *cli.Context) error {
+	log.Debug().Msg("In entryPointHolidayImport")
+
+	fileName := c.String("file")
+	icsFileName := c.Args().First()
+	overwrite := c.Bool("overwrite")
+
+	if icsFileName == "" {
+		return fmt.Errorf("%w: no iCalendar file given", ErrInvalidOptionCombination)
+	}
+
+	kind, err := flex.ParseDayKind(c.String("kind"))
+	if err != nil {
+		return err
+	}
+
+	icsFile, err := os.Open(icsFileName)
+	if err != nil {
+		return err
+	}
+	imported, err := flex.ParseICalendar(icsFile, kind, time.Local)
+	icsFile.Close()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	daysAdded := 0
+	for _, day := range imported {
+		if db.Calendar.Set(*day, overwrite) {
+			daysAdded++
+		}
+	}
+	db.Calendar.Sort()
+
+	log.Info().
+		Str("ics_file", icsFileName).
+		Int("days_found", imported.Len()).
+		Int("days_added", daysAdded).
+		Msg("Imported calendar")
+
+	return saveDB(db)
+}
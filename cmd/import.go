@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointImportCSV(c *cli.Context) error {
+	log.Debug().Msg("In entryPointImportCSV")
+
+	fileName := c.String("file")
+	csvFileName := c.Args().First()
+
+	if csvFileName == "" {
+		return fmt.Errorf("%w: no CSV file given", ErrInvalidOptionCombination)
+	}
+
+	options, err := csvImportOptions(c)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	csvFile, err := openImportFile(csvFileName)
+	if err != nil {
+		return err
+	}
+	rows, rejected, err := flex.ReadCSVEntries(csvFile, options)
+	csvFile.Close()
+	if err != nil {
+		return err
+	}
+
+	// Rejected rows are reported before anything else, so they're seen even if the import fails
+	writeRejectedRows(importReport(c, db), csvFileName, rejected)
+	if err = checkRejectedRows(c, len(rejected), len(rows)+len(rejected)); err != nil {
+		return err
+	}
+
+	return importRows(c, db, rows)
+}
+
+func entryPointImportTimewarrior(c *cli.Context) error {
+	log.Debug().Msg("In entryPointImportTimewarrior")
+	return importIntervals(c, "Timewarrior", false, func(reader io.Reader) ([]flex.TimeInterval, []flex.RejectedRow, error) {
+		return flex.ReadTimewarrior(reader)
+	})
+}
+
+func entryPointImportTimeclock(c *cli.Context) error {
+	log.Debug().Msg("In entryPointImportTimeclock")
+	return importIntervals(c, "timeclock", true, func(reader io.Reader) ([]flex.TimeInterval, []flex.RejectedRow, error) {
+		return flex.ReadTimeclock(reader, time.Local)
+	})
+}
+
+// importIntervals imports the time worked in the files given as arguments, for the import commands
+// reading time intervals. Intervals go to the first of their tags naming an existing customer,
+// or else the last tag if lastTag is true, or else the customer flag.
+func importIntervals(
+	c *cli.Context,
+	kind string,
+	lastTag bool,
+	read func(io.Reader) ([]flex.TimeInterval, []flex.RejectedRow, error),
+) error {
+	fileName := c.String("file")
+	if c.NArg() == 0 {
+		return fmt.Errorf("%w: no %s file given", ErrInvalidOptionCombination, kind)
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	intervals := make([]flex.TimeInterval, 0)
+	rejectedCount := 0
+	for _, inputFileName := range c.Args().Slice() {
+		inputFile, err := openImportFile(inputFileName)
+		if err != nil {
+			return err
+		}
+		found, rejected, err := read(inputFile)
+		inputFile.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", inputFileName, err)
+		}
+		writeRejectedRows(importReport(c, db), inputFileName, rejected)
+		intervals = append(intervals, found...)
+		rejectedCount += len(rejected)
+	}
+	if err := checkRejectedRows(c, rejectedCount, len(intervals)+rejectedCount); err != nil {
+		return err
+	}
+
+	return importRows(c, db, flex.IntervalRows(intervals, time.Local, tagCustomer(db, lastTag)))
+}
+
+// tagCustomer returns a function giving the first of the tags that names an existing customer,
+// or else the last tag if lastTag is true, or else an empty string
+func tagCustomer(db *flex.DB, lastTag bool) func([]string) string {
+	return func(tags []string) string {
+		for _, tag := range tags {
+			if customer, err := db.GetCustomer(tag); err == nil {
+				return customer.Name
+			}
+		}
+		if lastTag && len(tags) > 0 {
+			return tags[len(tags)-1]
+		}
+		return ""
+	}
+}
+
+// openImportFile opens the named file for reading, where - is stdin
+func openImportFile(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// importReport returns where to report what is imported and rejected, see reportWriter,
+// which is stdout on a dry run, as the DB is not saved then
+func importReport(c *cli.Context, db *flex.DB) io.Writer {
+	if c.Bool("dry-run") {
+		return os.Stdout
+	}
+	return reportWriter(db)
+}
+
+// checkRejectedRows returns an error if any of total rows are rejected,
+// unless on a dry run or told to skip them
+func checkRejectedRows(c *cli.Context, rejected, total int) error {
+	if rejected == 0 || c.Bool("dry-run") || c.Bool("skip-invalid") {
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: %d of %d, fix them or use --skip-invalid to import the rest",
+		ErrRejectedRows,
+		rejected,
+		total,
+	)
+}
+
+// importRows adds the rows to their customers according to the policy flag and saves the DB,
+// or shows what would be done on a dry run
+func importRows(c *cli.Context, db *flex.DB, rows []flex.ImportRow) error {
+	policy, err := flex.ParseTransferPolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+
+	results, err := db.ImportRows(rows, customerFlag(c), policy)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("dry-run") {
+		writeImportResults(importReport(c, db), "Would import", db.Calendar, results)
+		return nil
+	}
+
+	if err = saveDB(db); err != nil {
+		return err
+	}
+	writeImportResults(importReport(c, db), "Imported", db.Calendar, results)
+
+	return nil
+}
+
+// csvImportOptions returns the options for reading CSV, from the flags
+func csvImportOptions(c *cli.Context) (flex.CSVImportOptions, error) {
+	options := flex.CSVImportOptions{
+		DateLayout: c.String("date-format"),
+	}
+	var err error
+	options.Mapping, err = flex.ParseCSVMapping(c.String("map"))
+	if err != nil {
+		return options, err
+	}
+	options.AmountFormat, err = flex.ParseAmountFormat(c.String("amount-format"))
+	if err != nil {
+		return options, err
+	}
+	delimiter := c.String("delimiter")
+	if delimiter == `\t` {
+		delimiter = "\t"
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return options, fmt.Errorf("%w: delimiter must be a single character, got %q", ErrInvalidOptionCombination, delimiter)
+	}
+	options.Delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	return options, nil
+}
+
+func writeRejectedRows(writer io.Writer, inputFileName string, rejected []flex.RejectedRow) {
+	if len(rejected) == 0 {
+		return
+	}
+	fmt.Fprintln(writer, "Rejected rows:")
+	for _, row := range rejected {
+		fmt.Fprintf(writer, "\t%s:%d: %v\n", inputFileName, row.Line, row.Err)
+	}
+}
+
+func writeImportResults(writer io.Writer, verb string, calendar flex.Calendar, results []flex.ImportResult) {
+	for _, result := range results {
+		fmt.Fprintf(
+			writer,
+			"%s %s, %s flex, for %s\n",
+			verb,
+			countEntries(result.Transferred.Len()),
+			formatDuration(result.Transferred.GetTotalFlex()),
+			result.Customer,
+		)
+		for _, entry := range result.Transferred {
+			writeEntry(writer, calendar, entry)
+		}
+		if result.Replaced.Len() > 0 {
+			fmt.Fprintf(writer, "Replaced %s of %s:\n", countEntries(result.Replaced.Len()), result.Customer)
+			for _, entry := range result.Replaced {
+				writeEntry(writer, calendar, entry)
+			}
+		}
+		if result.Skipped.Len() > 0 {
+			fmt.Fprintf(writer, "Skipped %s, as %s has entries for the same dates:\n", countEntries(result.Skipped.Len()), result.Customer)
+			for _, entry := range result.Skipped {
+				writeEntry(writer, calendar, entry)
+			}
+		}
+	}
+}
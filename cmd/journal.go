@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// historyTimeFormat is how record times are shown by the history command
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// mutating wraps an action that modifies the DB, so that it runs while holding the lock,
+// and what it changed is recorded in the journal
+func mutating(action cli.ActionFunc) cli.ActionFunc {
+	return withLock(withJournal(action))
+}
+
+// journalCommand returns the command line as recorded in the journal
+func journalCommand() string {
+	return strings.Join(os.Args[1:], " ")
+}
+
+// journalFileName returns the DB file name to keep a journal for, or an empty string
+// if the DB is read from stdin or written to stdout, where there is no file to keep a journal next to.
+func journalFileName(c *cli.Context) string {
+	fileName := c.String("file")
+	if fileName == "-" {
+		return ""
+	}
+	return fileName
+}
+
+// withJournal wraps an action that modifies the DB, and appends a record of the changes
+// it made to the journal. The DB is read before and after the action, so the action itself
+// does not need to know about the journal. Fields that are not part of the DB are read past,
+// so that what doctor --fix changes in a file with such fields is recorded too,
+// and a file from an older schema version is read as it is, so that what migrate changes is recorded.
+func withJournal(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		fileName := journalFileName(c)
+		if fileName == "" {
+			return action(c)
+		}
+
+		before, err := openDBLenient(fileName)
+		if before == nil {
+			// Let the action report the problem with the file
+			return action(c)
+		}
+		if err != nil {
+			log.Debug().Err(err).Msg("Journal: no previous DB state")
+		}
+
+		if err = action(c); err != nil {
+			return err
+		}
+
+		after, err := openDBLenient(fileName)
+		if after == nil {
+			return err
+		}
+		changes, err := flex.DiffDB(before, after)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			log.Debug().Msg("Journal: nothing changed")
+			return nil
+		}
+
+		journal, err := flex.OpenJournal(fileName)
+		if err != nil {
+			return err
+		}
+		record, err := journal.Append(flex.JournalChange, journalCommand(), 0, changes)
+		if err != nil {
+			return err
+		}
+		log.Debug().
+			Int("seq", record.Seq).
+			Int("changes", len(changes)).
+			Msg("Journal: recorded change")
+
+		return nil
+	}
+}
+
+// changedCustomers returns the names of the customers in the given changes, for display
+func changedCustomers(changes []flex.CustomerChange) string {
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		if change.Customer == "" {
+			names = append(names, "<settings>")
+			continue
+		}
+		names = append(names, change.Customer)
+	}
+	return strings.Join(names, ", ")
+}
+
+func entryPointUndo(c *cli.Context) error {
+	log.Debug().Msg("In entryPointUndo")
+	return replayJournal(c, true)
+}
+
+func entryPointRedo(c *cli.Context) error {
+	log.Debug().Msg("In entryPointRedo")
+	return replayJournal(c, false)
+}
+
+// replayJournal reverts the last change if undo is true, or applies the last undone change again if not
+func replayJournal(c *cli.Context, undo bool) error {
+	fileName := journalFileName(c)
+	force := c.Bool("force")
+
+	if fileName == "" {
+		return fmt.Errorf("%w: undo and redo need a DB file", ErrInvalidOptionCombination)
+	}
+
+	journal, err := flex.OpenJournal(fileName)
+	if err != nil {
+		return err
+	}
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	kind := flex.JournalRedo
+	replay := journal.Redo
+	if undo {
+		kind = flex.JournalUndo
+		replay = journal.Undo
+	}
+
+	record, err := replay(db, force)
+	if err != nil {
+		return err
+	}
+	if err = saveDB(db); err != nil {
+		return err
+	}
+
+	changes := record.Changes
+	if undo {
+		changes = flex.InvertChanges(changes)
+	}
+	if _, err = journal.Append(kind, journalCommand(), record.Seq, changes); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int("seq", record.Seq).
+		Str("command", record.Command).
+		Str("customers", changedCustomers(record.Changes)).
+		Msgf("%s done", kind)
+
+	return nil
+}
+
+func entryPointHistory(c *cli.Context) error {
+	log.Debug().Msg("In entryPointHistory")
+
+	fileName := journalFileName(c)
+	limit := c.Int("limit")
+
+	if fileName == "" {
+		return fmt.Errorf("%w: history needs a DB file", ErrInvalidOptionCombination)
+	}
+
+	journal, err := flex.OpenJournal(fileName)
+	if err != nil {
+		return err
+	}
+
+	records := journal.Records
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	for _, record := range records {
+		description := record.Command
+		if record.Kind != flex.JournalChange {
+			description = fmt.Sprintf("#%d", record.Ref)
+			if ref := journal.Get(record.Ref); ref != nil {
+				description += " " + ref.Command
+			}
+		}
+		fmt.Printf(
+			"#%d\t%s\t%s\t%s [%s]\n",
+			record.Seq,
+			record.Time.Local().Format(historyTimeFormat),
+			record.Kind,
+			description,
+			changedCustomers(record.Changes),
+		)
+	}
+
+	if next := journal.Undoable(); next != nil {
+		fmt.Printf("Next undo: #%d %s\n", next.Seq, next.Command)
+	}
+	if next := journal.Redoable(); next != nil {
+		fmt.Printf("Next redo: #%d %s\n", next.Seq, next.Command)
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -22,14 +24,27 @@ func entryPointList(c *cli.Context) error {
 	}
 
 	fileName := c.String("file")
-	customerName := c.String("customer")
+	customerName := customerFlag(c)
 	verbose := c.Bool("verbose")
 	all := c.Bool("all")
-	customerSort := c.String("customer-sort")
-	entrySort := c.String("entry-sort")
-	date := c.Timestamp("date")
-	from := c.Timestamp("from")
-	to := c.Timestamp("to")
+	customerSort := stringFlag(c, "customer-sort", config.CustomerSort)
+	entrySort := stringFlag(c, "entry-sort", config.EntrySort)
+	grep := c.String("grep")
+	templateFile := c.String("template")
+	output := c.String("output")
+	if templateFile == "" {
+		// A configured output format is only used without a template, as they can't be combined
+		output = stringFlag(c, "output", config.Output)
+	}
+
+	date, err := dateFlag(c, "date")
+	if err != nil {
+		return err
+	}
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
 
 	log.Debug().
 		Str("FileName", fileName).
@@ -41,8 +56,19 @@ func entryPointList(c *cli.Context) error {
 		Str("Date", tfmt(date)).
 		Str("From", tfmt(from)).
 		Str("To", tfmt(to)).
+		Str("Grep", grep).
+		Str("Output", output).
+		Str("Template", templateFile).
 		Send()
 
+	format, err := parseOutputFormat(output)
+	if err != nil {
+		return err
+	}
+	if templateFile != "" && format != outputText {
+		return fmt.Errorf("%w: template and output", ErrInvalidOptionCombination)
+	}
+
 	db, err := openDB(fileName)
 	if err != nil {
 		if db == nil {
@@ -70,347 +96,235 @@ func entryPointList(c *cli.Context) error {
 		entrySortValue = value
 	}
 
-	builder := strings.Builder{}
-
-	if customer != nil && date != nil {
-		if err := listSpecificDateForCustomer(&builder, customer, *date); err != nil {
-			return err
-		}
-	} else if customer != nil && date == nil && from == nil && to == nil && !verbose {
-		if err := listShortSummaryForCustomer(&builder, customer); err != nil {
-			return err
-		}
-	} else if customer != nil && date == nil && from == nil && to == nil && verbose {
-		if err := listAllForCustomer(&builder, customer, entrySortValue); err != nil {
-			return err
-		}
-	} else if customer != nil && date == nil && (from != nil || to != nil) && !verbose {
-		if err := listDateRangeSummaryForCustomer(&builder, customer, from, to); err != nil {
-			return err
-		}
-	} else if customer != nil && date == nil && (from != nil || to != nil) && verbose {
-		if err := listDateRangeForCustomer(&builder, customer, entrySortValue, from, to); err != nil {
-			return err
-		}
-	} else if all && date != nil {
-		if err := listSpecificDateForAllCustomers(&builder, db, *date, customerSortValue); err != nil {
-			return err
-		}
-	} else if all && date == nil && from == nil && to == nil && !verbose {
-		if err := listShortSummaryForAllCustomers(&builder, db, customerSortValue); err != nil {
-			return err
-		}
-	} else if all && date == nil && from == nil && to == nil && verbose {
-		if err := listAllForAllCustomers(&builder, db, customerSortValue, entrySortValue); err != nil {
-			return err
-		}
-	} else if all && date == nil && (from != nil || to != nil) && !verbose {
-		if err := listDateRangeSummaryForAllCustomers(&builder, db, customerSortValue, from, to); err != nil {
-			return err
-		}
-	} else if all && date == nil && (from != nil || to != nil) && verbose {
-		if err := listDateRangeForAllCustomers(&builder, db, customerSortValue, entrySortValue, from, to); err != nil {
+	var commentExpr *regexp.Regexp
+	if grep != "" {
+		commentExpr, err = regexp.Compile(grep)
+		if err != nil {
 			return err
 		}
 	}
 
-	fmt.Print(builder.String())
-
-	return nil
-}
+	customers := selectCustomers(db, customer, all, customerSortValue)
+	if customers == nil {
+		return nil
+	}
 
-func listSpecificDateForCustomer(writer io.Writer, customer *flex.Customer, date time.Time) error {
-	if customer == nil {
-		return flex.ErrNilCustomer
+	// Listing all entries of a customer needs some to list
+	if customer != nil && verbose && date == nil && customer.Entries.Len() == 0 {
+		return flex.ErrNoEntries
 	}
-	entry, err := customer.GetEntry(date)
+
+	listings, err := selectListings(db, customers, customer != nil, date, from, to, commentExpr)
 	if err != nil {
 		return err
 	}
-
-	fmt.Fprintf(
-		writer,
-		"%s:\n\t* %s: %v\n",
-		customer.Name,
-		entry.Date.Format(flex.ShortDateFormat),
-		entry.Amount,
-	)
-
-	return nil
-}
-
-func listSpecificDateForAllCustomers(writer io.Writer, db *flex.DB, date time.Time, sortOrder flex.CustomerSortOrder) error {
-	if db == nil || db.IsEmpty() {
-		return flex.ErrEmptyDB
+	for _, listing := range listings {
+		listing.entries.Sort(entrySortValue)
 	}
-	db.Customers.Sort(sortOrder)
-	for _, customer := range db.Customers {
-		entry, err := customer.GetEntry(date)
-		if err != nil {
-			continue
-		}
-		fmt.Fprintf(
-			writer,
-			"%s:\n\t* %s: %v\n",
-			customer.Name,
-			entry.Date.Format(flex.ShortDateFormat),
-			entry.Amount,
-		)
+
+	if templateFile != "" {
+		return writeTemplateListings(os.Stdout, templateFile, db, listings)
 	}
-	return nil
-}
 
-func listShortSummaryForCustomer(writer io.Writer, customer *flex.Customer) error {
-	if customer == nil {
-		return flex.ErrNilCustomer
+	switch format {
+	case outputJSON:
+		return writeJSONListings(os.Stdout, db.Calendar, listings)
+	case outputCSV:
+		return writeDelimitedListings(os.Stdout, ',', listings)
+	case outputTSV:
+		return writeDelimitedListings(os.Stdout, '\t', listings)
 	}
 
-	fmt.Fprintf(
-		writer,
-		"%s: %v\n",
-		customer.Name,
-		customer.GetTotalFlex(),
-	)
-	return nil
-}
+	builder := strings.Builder{}
 
-func listShortSummaryForAllCustomers(writer io.Writer, db *flex.DB, sortOrder flex.CustomerSortOrder) error {
-	if db == nil || db.IsEmpty() {
-		return flex.ErrEmptyDB
-	}
-	db.Customers.Sort(sortOrder)
-	formatStr := fmt.Sprintf("%s%d%s", "%-", db.Customers.LongestName(), "s : %v\n")
-	for _, customer := range db.Customers {
-		fmt.Fprintf(
-			writer,
-			formatStr,
-			customer.Name,
-			customer.GetTotalFlex(),
-		)
+	switch {
+	case date != nil:
+		writeDateListings(&builder, db.Calendar, listings)
+	case verbose:
+		writeVerboseListings(&builder, db.Calendar, listings)
+	case customer != nil:
+		writeSummaryListings(&builder, listings, 0)
+	default:
+		writeSummaryListings(&builder, listings, db.Customers.LongestName())
 	}
-	return nil
-}
 
-func listAllForAllCustomers(writer io.Writer, db *flex.DB, customerSortOrder flex.CustomerSortOrder, entrySortOrder flex.EntrySortOrder) error {
-	if db == nil || db.IsEmpty() {
-		return flex.ErrEmptyDB
-	}
-	customerFormat := "%s: %v\n"
-	entryFormat := "\t* %s: %v\n"
-	db.Customers.Sort(customerSortOrder)
-	for _, customer := range db.Customers {
-		fmt.Fprintf(
-			writer,
-			customerFormat,
-			customer.Name,
-			customer.GetTotalFlex(),
-		)
-		customer.Entries.Sort(entrySortOrder)
-		for _, entry := range customer.Entries {
-			fmt.Fprintf(
-				writer,
-				entryFormat,
-				entry.Date.Format(flex.ShortDateFormat),
-				entry.Amount,
-			)
-		}
-	}
+	fmt.Print(builder.String())
+
 	return nil
 }
 
-func listAllForCustomer(writer io.Writer, customer *flex.Customer, sortOrder flex.EntrySortOrder) error {
-	if customer == nil {
-		return flex.ErrNilCustomer
-	}
-	if customer.Entries == nil || customer.Entries.Len() == 0 {
-		return flex.ErrNoEntries
-	}
-
-	customer.Entries.Sort(sortOrder)
-
-	fmt.Fprintf(writer, "%s: %v\n", customer.Name, customer.GetTotalFlex())
-	for _, entry := range customer.Entries {
-		fmt.Fprintf(
-			writer,
-			"\t* %s: %v\n",
-			entry.Date.Format(flex.ShortDateFormat),
-			entry.Amount,
-		)
+// selectCustomers returns the given customer if not nil, or all customers in the given order if all is true.
+// Returns nil if neither.
+func selectCustomers(db *flex.DB, customer *flex.Customer, all bool, sortOrder flex.CustomerSortOrder) flex.Customers {
+	switch {
+	case customer != nil:
+		return flex.Customers{customer}
+	case all:
+		db.Customers.Sort(sortOrder)
+		return db.Customers
+	default:
+		return nil
 	}
+}
 
-	return nil
+// customerListing is a customer along with the entries selected for listing
+type customerListing struct {
+	customer *flex.Customer
+	entries  flex.Entries
+	// daysOff holds the calendar days within the listed range, if a range was given
+	daysOff flex.Calendar
+	// history is the balance over time of all the customers entries, regardless of filters
+	history flex.BalanceHistory
 }
 
-func listDateRangeSummaryForCustomer(writer io.Writer, customer *flex.Customer, from, to *time.Time) error {
-	if customer == nil {
-		return flex.ErrNilCustomer
+// dateRange returns from and to, with the date of the first and last entry
+// of the given customer filled in for any of them that is nil.
+// A customer without entries has no range.
+func dateRange(customer *flex.Customer, from, to *time.Time) (time.Time, time.Time, error) {
+	if customer.Entries.Len() == 0 {
+		return time.Time{}, time.Time{}, flex.ErrNoEntries
 	}
-	if customer.Entries == nil {
-		return flex.ErrNoEntries
-	}
-
 	if from == nil {
 		firstDate, err := customer.Entries.FirstDate()
 		if err != nil {
-			return err
+			return time.Time{}, time.Time{}, err
 		}
 		from = firstDate
 	}
-
 	if to == nil {
 		lastDate, err := customer.Entries.LastDate()
 		if err != nil {
-			return err
+			return time.Time{}, time.Time{}, err
 		}
 		to = lastDate
 	}
-
-	filteredEntries := customer.Entries.FilterByDateRange(*from, *to)
-	fmt.Fprintf(
-		writer,
-		"%s: %v\n",
-		customer.Name,
-		filteredEntries.GetTotalFlex(),
-	)
-
-	return nil
+	return *from, *to, nil
 }
 
-func listDateRangeSummaryForAllCustomers(writer io.Writer, db *flex.DB, sortOrder flex.CustomerSortOrder, from, to *time.Time) error {
-	if db == nil || db.IsEmpty() {
-		return flex.ErrEmptyDB
-	}
-
-	var firstDate *time.Time
-	var lastDate *time.Time
-	var err error
-	formatStr := fmt.Sprintf("%s%d%s", "%-", db.Customers.LongestName(), "s : %v\n")
-	db.Customers.Sort(sortOrder)
-	for _, customer := range db.Customers {
-		if customer.Entries == nil || customer.Entries.Len() == 0 {
-			continue
-		}
-		if from == nil {
-			firstDate, err = customer.Entries.FirstDate()
+// selectListings picks the entries to list for each of the given customers.
+// If single is true, failing to find entries for the customer is an error,
+// otherwise customers without matching entries are skipped when a date or range is given.
+// If commentExpr is not nil, only entries with a matching comment are selected, and customers
+// without any such entries are skipped.
+func selectListings(db *flex.DB, customers flex.Customers, single bool, date, from, to *time.Time, commentExpr *regexp.Regexp) ([]*customerListing, error) {
+	listings := make([]*customerListing, 0, customers.Len())
+	for _, customer := range customers {
+		listing := &customerListing{customer: customer, history: customer.BalanceHistory(nil, nil)}
+		switch {
+		case date != nil:
+			entries, err := customer.GetEntries(*date)
 			if err != nil {
+				if single {
+					return nil, err
+				}
 				continue
 			}
-		} else {
-			firstDate = from
-		}
-		if to == nil {
-			lastDate, err = customer.Entries.LastDate()
+			listing.entries = entries
+		case from != nil || to != nil:
+			first, last, err := dateRange(customer, from, to)
 			if err != nil {
+				if single {
+					return nil, err
+				}
 				continue
 			}
-		} else {
-			lastDate = to
+			listing.entries = customer.Entries.FilterByDateRange(first, last)
+			listing.daysOff = db.Calendar.FilterByDateRange(first, last)
+		default:
+			listing.entries = append(make(flex.Entries, 0, customer.Entries.Len()), customer.Entries...)
 		}
-		filteredEntries := customer.Entries.FilterByDateRange(*firstDate, *lastDate)
-		fmt.Fprintf(
-			writer,
-			formatStr,
-			customer.Name,
-			filteredEntries.GetTotalFlex(),
-		)
+		if commentExpr != nil {
+			listing.entries = listing.entries.FilterByComment(commentExpr)
+			if listing.entries.Len() == 0 && !single {
+				continue
+			}
+		}
+		listings = append(listings, listing)
 	}
-	return nil
+	return listings, nil
 }
 
-func listDateRangeForCustomer(writer io.Writer, customer *flex.Customer, sortOrder flex.EntrySortOrder, from, to *time.Time) error {
-	if customer == nil {
-		return flex.ErrNilCustomer
-	}
-	if customer.Entries == nil {
-		return flex.ErrNoEntries
-	}
-
-	if from == nil {
-		firstDate, err := customer.Entries.FirstDate()
-		if err != nil {
-			return err
-		}
-		from = firstDate
+// daysOffSuffix returns a note on how many calendar days are within the listed range, if any
+func daysOffSuffix(listing *customerListing) string {
+	switch listing.daysOff.Len() {
+	case 0:
+		return ""
+	case 1:
+		return " (1 day off)"
+	default:
+		return fmt.Sprintf(" (%d days off)", listing.daysOff.Len())
 	}
+}
 
-	if to == nil {
-		lastDate, err := customer.Entries.LastDate()
-		if err != nil {
-			return err
-		}
-		to = lastDate
-	}
+func writeEntry(writer io.Writer, calendar flex.Calendar, entry *flex.Entry) {
+	writeEntryLine(writer, calendar, entry, nil)
+}
 
-	filteredEntries := customer.Entries.FilterByDateRange(*from, *to)
-	filteredEntries.Sort(sortOrder)
+// writeBalanceEntry writes the entry along with the balance at the end of its date
+func writeBalanceEntry(writer io.Writer, calendar flex.Calendar, entry *flex.Entry, history flex.BalanceHistory) {
+	balance := history.At(entry.Date)
+	writeEntryLine(writer, calendar, entry, &balance)
+}
 
+func writeEntryLine(writer io.Writer, calendar flex.Calendar, entry *flex.Entry, balance *time.Duration) {
 	fmt.Fprintf(
 		writer,
-		"%s: %v\n",
-		customer.Name,
-		filteredEntries.GetTotalFlex(),
+		"\t* %s: %s",
+		entry.Date.Format(flex.ShortDateFormat),
+		formatDuration(entry.Amount),
 	)
-	for _, entry := range filteredEntries {
-		fmt.Fprintf(
-			writer,
-			"\t* %s: %v\n",
-			entry.Date.Format(flex.ShortDateFormat),
-			entry.Amount,
-		)
+	if balance != nil {
+		fmt.Fprintf(writer, " (balance: %s)", formatDuration(*balance))
 	}
-
-	return nil
+	if day := calendar.Get(entry.Date); day != nil {
+		fmt.Fprintf(writer, " [%s]", day.Format(formatDuration))
+	}
+	if entry.ID != "" {
+		fmt.Fprintf(writer, " id:%s", entry.ID)
+	}
+	if entry.Comment != "" {
+		fmt.Fprintf(writer, " # %s", entry.Comment)
+	}
+	fmt.Fprintln(writer)
 }
 
-func listDateRangeForAllCustomers(writer io.Writer, db *flex.DB, customerSortOrder flex.CustomerSortOrder, entrySortOrder flex.EntrySortOrder, from, to *time.Time) error {
-	if db == nil || db.IsEmpty() {
-		return flex.ErrEmptyDB
+func writeDateListings(writer io.Writer, calendar flex.Calendar, listings []*customerListing) {
+	for _, listing := range listings {
+		fmt.Fprintf(writer, "%s:\n", listing.customer.Name)
+		for _, entry := range listing.entries {
+			writeBalanceEntry(writer, calendar, entry, listing.history)
+		}
 	}
+}
 
-	var firstDate *time.Time
-	var lastDate *time.Time
-	var err error
-	customerFormat := "%s: %v\n"
-	entryFormat := "\t* %s: %v\n"
-	db.Customers.Sort(customerSortOrder)
-
-	for _, customer := range db.Customers {
-		if customer.Entries == nil || customer.Entries.Len() == 0 {
-			continue
-		}
-		if from == nil {
-			firstDate, err = customer.Entries.FirstDate()
-			if err != nil {
-				continue
-			}
-		} else {
-			firstDate = from
-		}
-		if to == nil {
-			lastDate, err = customer.Entries.LastDate()
-			if err != nil {
-				continue
-			}
-		} else {
-			lastDate = to
-		}
-		filteredEntries := customer.Entries.FilterByDateRange(*firstDate, *lastDate)
+func writeVerboseListings(writer io.Writer, calendar flex.Calendar, listings []*customerListing) {
+	for _, listing := range listings {
 		fmt.Fprintf(
 			writer,
-			customerFormat,
-			customer.Name,
-			filteredEntries.GetTotalFlex(),
+			"%s: %s%s\n",
+			listing.customer.Name,
+			formatDuration(listing.entries.GetTotalFlex()),
+			daysOffSuffix(listing),
 		)
-		filteredEntries.Sort(entrySortOrder)
-		for _, entry := range filteredEntries {
-			fmt.Fprintf(
-				writer,
-				entryFormat,
-				entry.Date.Format(flex.ShortDateFormat),
-				entry.Amount,
-			)
+		for _, entry := range listing.entries {
+			writeBalanceEntry(writer, calendar, entry, listing.history)
 		}
 	}
+}
 
-	return nil
+// writeSummaryListings writes the total for each listing, with names padded to nameWidth if it's not 0
+func writeSummaryListings(writer io.Writer, listings []*customerListing, nameWidth int) {
+	formatStr := "%s: %s%s\n"
+	if nameWidth > 0 {
+		formatStr = fmt.Sprintf("%s%d%s", "%-", nameWidth, "s : %s%s\n")
+	}
+	for _, listing := range listings {
+		fmt.Fprintf(
+			writer,
+			formatStr,
+			listing.customer.Name,
+			formatDuration(listing.entries.GetTotalFlex()),
+			daysOffSuffix(listing),
+		)
+	}
 }
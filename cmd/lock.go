@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// withLock wraps an action that modifies the DB, so that the whole open, modify and save
+// cycle runs while holding an exclusive lock on the DB file.
+// Nothing is locked when reading from stdin or writing to stdout.
+func withLock(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		fileName := c.String("file")
+		if fileName == "" || fileName == "-" {
+			return action(c)
+		}
+
+		lock, err := flex.AcquireLock(fileName, c.Duration("lock-timeout"))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				log.Error().Err(err).Msg("Failed to release lock")
+			}
+		}()
+
+		return action(c)
+	}
+}
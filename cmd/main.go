@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/oddlid/flextime/flex"
@@ -52,10 +53,133 @@ func getCompiledDate() time.Time {
 	return compiledTime
 }
 
+func scheduleFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "customer",
+			Aliases: []string{"c"},
+			Usage:   "The customer `name` for whom the schedule applies",
+		},
+		&cli.BoolFlag{
+			Name:  "default",
+			Usage: "Use the default schedule for customers without their own",
+		},
+	}
+}
+
+// transferFlags are the flags for move and copy, where verb is either of them
+func transferFlags(verb string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from-customer",
+			Usage: fmt.Sprintf("The customer `name` to %s entries from", strings.ToLower(verb)),
+		},
+		&cli.StringFlag{
+			Name:  "to-customer",
+			Usage: fmt.Sprintf("The customer `name` to %s entries to, added if needed", strings.ToLower(verb)),
+		},
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   verb + " all entries",
+		},
+		&cli.StringFlag{
+			Name:  "id",
+			Usage: verb + " the entry with this `ID`",
+		},
+		&cli.StringFlag{
+			Name:    "date",
+			Aliases: []string{"d"},
+			Usage:   verb + " entries for this specific `DATE`",
+		},
+		&cli.StringFlag{
+			Name:    "from",
+			Aliases: []string{"f"},
+			Usage:   verb + " entries starting from this `DATE`",
+		},
+		&cli.StringFlag{
+			Name:    "to",
+			Aliases: []string{"t"},
+			Usage:   verb + " entries up to this `DATE`",
+		},
+		periodFlag(),
+		&cli.StringFlag{
+			Name:  "policy",
+			Value: flex.TransferFail.String(),
+			Usage: fmt.Sprintf(
+				"What to do with dates the target already has entries for (options: %s)",
+				flex.TransferPolicyOptions(),
+			),
+		},
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Aliases: []string{"n"},
+			Usage:   "Show what would be done, without saving",
+		},
+	}
+}
+
+// importFlags are the flags shared by the import commands, where defaultFor says what the customer flag is for
+func importFlags(defaultFor string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "customer",
+			Aliases: []string{"c"},
+			Usage:   "Customer `name` for " + defaultFor,
+		},
+		&cli.StringFlag{
+			Name:  "policy",
+			Value: flex.TransferFail.String(),
+			Usage: fmt.Sprintf(
+				"What to do with dates a customer already has entries for (options: %s)",
+				flex.TransferPolicyOptions(),
+			),
+		},
+		&cli.BoolFlag{
+			Name:  "skip-invalid",
+			Usage: "Import the valid rows even if some are rejected",
+		},
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Aliases: []string{"n"},
+			Usage:   "Show what would be imported and which rows are rejected, without saving",
+		},
+	}
+}
+
+// exportFlags are the flags shared by the export commands
+func exportFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "customer",
+			Aliases: []string{"c"},
+			Usage:   "The customer `name` to export entries for, default the default customer",
+		},
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   "Export entries for all customers",
+		},
+		&cli.StringFlag{
+			Name:    "from",
+			Aliases: []string{"f"},
+			Usage:   "Export entries starting from this `DATE`",
+		},
+		&cli.StringFlag{
+			Name:    "to",
+			Aliases: []string{"t"},
+			Usage:   "Export entries up to this `DATE`",
+		},
+		periodFlag(),
+		durationFormatFlag(),
+	}
+}
+
 func main() {
 	app := &cli.App{
 		Name:                 "flextime",
 		Usage:                "Track flextime +/-",
+		Description:          "Flags taking a DATE accept " + flex.DateInputHelp,
 		Copyright:            "(C) 2021 Odd Eivind Ebbesen",
 		Compiled:             getCompiledDate(),
 		Version:              getBuildVersion(),
@@ -80,15 +204,54 @@ func main() {
 			} else {
 				zerolog.SetGlobalLevel(zerolog.InfoLevel)
 			}
+			if err := loadConfig(c); err != nil {
+				return err
+			}
+			backupCount = c.Int("backups")
+			format, err := flex.ParseDurationFormat(stringFlag(c, "duration-format", config.DurationFormat))
+			if err != nil {
+				return err
+			}
+			durationFormat = format
 			return nil
 		},
 		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "config",
+				EnvVars:   []string{flex.ConfigFileEnv},
+				Value:     defaultConfigFileName(),
+				TakesFile: true,
+				Usage:     "TOML config `file` with defaults for flags not given",
+			},
 			&cli.StringFlag{
 				Name:    "file",
 				Aliases: []string{"f"},
 				EnvVars: []string{"FLEXTIME_FILE"},
 				Usage:   "JSON `file` to load/save data from",
 			},
+			&cli.IntFlag{
+				Name:    "backups",
+				Aliases: []string{"b"},
+				EnvVars: []string{"FLEXTIME_BACKUPS"},
+				Value:   flex.DefaultBackupCount,
+				Usage:   "Number of backup copies (file.1, file.2, ...) to keep when saving, 0 to disable",
+			},
+			&cli.DurationFlag{
+				Name:    "lock-timeout",
+				EnvVars: []string{"FLEXTIME_LOCK_TIMEOUT"},
+				Value:   flex.DefaultLockTimeout,
+				Usage:   "How long to wait for another process to release the DB file",
+			},
+			&cli.StringFlag{
+				Name:    "duration-format",
+				Aliases: []string{"D"},
+				EnvVars: []string{"FLEXTIME_DURATION_FORMAT"},
+				Value:   flex.DurationFormatGo.String(),
+				Usage: fmt.Sprintf(
+					"How amounts of time are shown in text output (options: %s)",
+					flex.DurationFormatOptions(),
+				),
+			},
 			&cli.StringFlag{
 				Name:    "log-level",
 				Aliases: []string{"l"},
@@ -107,30 +270,417 @@ func main() {
 				Name:    "add",
 				Aliases: []string{"set"},
 				Usage:   "Add or set flex time for a given customer",
-				Action:  entryPointAdd,
+				Action:  mutating(entryPointAdd),
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    "customer",
 						Aliases: []string{"c"},
 						Usage:   "The customer `name` for whom to add flex",
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "date",
 						Aliases: []string{"d"},
-						Usage:   "Date (`YYYY-MM-DD`) to add flex for",
-						Layout:  flex.ShortDateFormat,
-						// Value:       cli.NewTimestamp(time.Now()),
-						// DefaultText: time.Now().Format(flex.ShortDateFormat),
+						Usage:   "`DATE` to add flex for, default today",
 					},
-					&cli.DurationFlag{
+					&cli.StringFlag{
 						Name:    "amount",
 						Aliases: []string{"a"},
-						Usage:   "Amount of flex time",
+						Usage:   fmt.Sprintf("`AMOUNT` of flex time, e.g. %s", flex.DurationInputHelp),
+					},
+					&cli.StringFlag{
+						Name:    "worked",
+						Aliases: []string{"w"},
+						Usage:   "Time actually worked, as for amount. Flex is calculated from the customers schedule",
+					},
+					&cli.StringFlag{
+						Name:    "comment",
+						Aliases: []string{"m"},
+						Usage:   "Comment for the entry",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"o"},
+						Usage:   "Overwrite all entries for the date if any already exists",
+					},
+					&cli.BoolFlag{
+						Name:    "append",
+						Aliases: []string{"A"},
+						Usage:   "Add another entry, even if entries for the date already exist",
+					},
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "Edit the entry with this `ID` instead of adding one",
+					},
+				},
+			},
+			{
+				Name:   "start",
+				Usage:  "Start a work session (clock in) for a given customer",
+				Action: mutating(entryPointStart),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer `name` to start a session for",
+					},
+					&cli.StringFlag{
+						Name:  "at",
+						Usage: "Start time (`HH:MM`) today, instead of now",
+					},
+				},
+			},
+			{
+				Name:   "stop",
+				Usage:  "Stop a work session (clock out) and record the flex for the day",
+				Action: mutating(entryPointStop),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer `name` to stop the session for",
+					},
+					&cli.StringFlag{
+						Name:  "at",
+						Usage: "Stop time (`HH:MM`) today, instead of now",
 					},
 					&cli.BoolFlag{
 						Name:    "overwrite",
 						Aliases: []string{"o"},
-						Usage:   "Overwrite if matching entry already exists",
+						Usage:   "Overwrite if a manually set entry exists for the date",
+					},
+				},
+			},
+			{
+				Name:   "status",
+				Usage:  "Show running work sessions",
+				Action: entryPointStatus,
+				Before: applyDurationFormat,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer `name` to show session status for",
+					},
+					durationFormatFlag(),
+				},
+			},
+			{
+				Name:  "customer",
+				Usage: "Rename, merge, show or describe customers",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "rename",
+						Usage:     "Give a customer a new name",
+						ArgsUsage: "OLD NEW",
+						Action:    mutating(entryPointCustomerRename),
+					},
+					{
+						Name:      "merge",
+						Usage:     "Move all entries from the second customer to the first, and remove the second",
+						ArgsUsage: "INTO FROM",
+						Action:    mutating(entryPointCustomerMerge),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "policy",
+								Aliases: []string{"p"},
+								Value:   flex.MergeFail.String(),
+								Usage: fmt.Sprintf(
+									"What to do with dates that have entries for both, where left is INTO (options: %s)",
+									flex.MergePolicyOptions(),
+								),
+							},
+							&cli.BoolFlag{
+								Name:    "dry-run",
+								Aliases: []string{"n"},
+								Usage:   "Show what would be merged, and the dates with entries for both, without merging",
+							},
+						},
+					},
+					{
+						Name:   "info",
+						Usage:  "Show details about a customer",
+						Action: entryPointCustomerInfo,
+						Before: applyDurationFormat,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "customer",
+								Aliases: []string{"c"},
+								Usage:   "The customer `name` to show",
+							},
+							durationFormatFlag(),
+						},
+					},
+					{
+						Name:   "set",
+						Usage:  "Set details about a customer",
+						Action: mutating(entryPointCustomerSet),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "customer",
+								Aliases: []string{"c"},
+								Usage:   "The customer `name` to change",
+							},
+							&cli.StringFlag{
+								Name:  "display-name",
+								Usage: "Longer `name` for people to read, empty to remove",
+							},
+							&cli.StringFlag{
+								Name:  "notes",
+								Usage: "Free `text` about the customer, empty to remove",
+							},
+							&cli.BoolFlag{
+								Name:  "archived",
+								Usage: "Mark the customer as no longer active, or active again with --archived=false",
+							},
+							&cli.StringFlag{
+								Name:  "start-date",
+								Usage: "`DATE` the work for the customer started, empty to remove",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "schedule",
+				Usage: "Show or set the expected work per weekday",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "show",
+						Usage:  "Show the schedule in effect for a customer, or the default",
+						Action: entryPointScheduleShow,
+						Before: applyDurationFormat,
+						Flags:  append(scheduleFlags(), durationFormatFlag()),
+					},
+					{
+						Name:      "set",
+						Usage:     "Set the schedule for a customer, or the default",
+						ArgsUsage: "mon-thu=8h,fri=6h",
+						Action:    mutating(entryPointScheduleSet),
+						Flags:     scheduleFlags(),
+					},
+					{
+						Name:   "clear",
+						Usage:  "Remove the schedule from a customer, or the default",
+						Action: mutating(entryPointScheduleClear),
+						Flags:  scheduleFlags(),
+					},
+				},
+			},
+			{
+				Name:    "holiday",
+				Aliases: []string{"calendar"},
+				Usage:   "Manage holidays and absences that change the expected work for a date",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "add",
+						Usage:  "Add a holiday or absence",
+						Action: mutating(entryPointHolidayAdd),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "date",
+								Aliases: []string{"d"},
+								Usage:   "`DATE` of the holiday or absence, or a longer period like 2022-W30 for every day in it",
+							},
+							&cli.StringFlag{
+								Name:    "to",
+								Aliases: []string{"t"},
+								Usage:   "Last `DATE`, to add several days at once",
+							},
+							&cli.StringFlag{
+								Name:    "name",
+								Aliases: []string{"n"},
+								Usage:   "Name or description of the day",
+							},
+							&cli.StringFlag{
+								Name:    "kind",
+								Aliases: []string{"k"},
+								Value:   flex.DayHoliday.String(),
+								Usage:   fmt.Sprintf("Kind of day (options: %s)", flex.DayKindOptions()),
+							},
+							&cli.DurationFlag{
+								Name:  "hours",
+								Usage: "Reduced amount of expected work, instead of none",
+							},
+							&cli.BoolFlag{
+								Name:    "overwrite",
+								Aliases: []string{"o"},
+								Usage:   "Overwrite if the date already exists in the calendar",
+							},
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List holidays and absences",
+						Action:  entryPointHolidayList,
+						Before:  applyDurationFormat,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "from",
+								Aliases: []string{"f"},
+								Usage:   "List days starting from this `DATE`",
+							},
+							&cli.StringFlag{
+								Name:    "to",
+								Aliases: []string{"t"},
+								Usage:   "List days up to this `DATE`",
+							},
+							periodFlag(),
+							durationFormatFlag(),
+						},
+					},
+					{
+						Name:    "rm",
+						Aliases: []string{"delete", "del"},
+						Usage:   "Remove holidays or absences",
+						Action:  mutating(entryPointHolidayRemove),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "date",
+								Aliases: []string{"d"},
+								Usage:   "`DATE` to remove, or a longer period like 2022-W30 for every day in it",
+							},
+							&cli.StringFlag{
+								Name:    "to",
+								Aliases: []string{"t"},
+								Usage:   "Last `DATE`, to remove several days at once",
+							},
+						},
+					},
+					{
+						Name:      "import",
+						Usage:     "Import holidays from an iCalendar file",
+						ArgsUsage: "file.ics",
+						Action:    mutating(entryPointHolidayImport),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "kind",
+								Aliases: []string{"k"},
+								Value:   flex.DayHoliday.String(),
+								Usage:   fmt.Sprintf("Kind of day for the imported events (options: %s)", flex.DayKindOptions()),
+							},
+							&cli.BoolFlag{
+								Name:    "overwrite",
+								Aliases: []string{"o"},
+								Usage:   "Overwrite dates already in the calendar",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Import flex entries from other formats",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "csv",
+						Usage:     "Import entries from a CSV file with a header line",
+						ArgsUsage: "file.csv",
+						Action:    mutating(entryPointImportCSV),
+						Flags: append(
+							[]cli.Flag{
+								&cli.StringFlag{
+									Name:    "map",
+									Aliases: []string{"m"},
+									Usage: "Which column to read each field from, like date=Date,amount=Hours,customer=Client,comment=Note" +
+										" (fields: date, amount, worked, customer, comment; each defaults to a column of the same name)",
+								},
+								&cli.StringFlag{
+									Name:  "date-format",
+									Value: flex.ShortDateFormat,
+									Usage: "Go time `layout` of the dates, like 02.01.2006 or 01/02/2006",
+								},
+								&cli.StringFlag{
+									Name:  "amount-format",
+									Value: flex.AmountFlex.String(),
+									Usage: fmt.Sprintf(
+										"How amount and worked are written (options: %s), where flex accepts %s",
+										flex.AmountFormatOptions(),
+										flex.DurationInputHelp,
+									),
+								},
+								&cli.StringFlag{
+									Name:  "delimiter",
+									Value: ",",
+									Usage: `Field delimiter, like ; or \t`,
+								},
+							},
+							importFlags("rows without a customer")...,
+						),
+					},
+					{
+						Name:      "timewarrior",
+						Aliases:   []string{"timew"},
+						Usage:     "Import time worked from Timewarrior data files, or the output of timew export",
+						ArgsUsage: "file.data|export.json|- ...",
+						Action:    mutating(entryPointImportTimewarrior),
+						Flags:     importFlags("intervals without a tag naming a customer"),
+					},
+					{
+						Name:      "timeclock",
+						Usage:     "Import time worked from a ledger/hledger timeclock file, by the last part of each account",
+						ArgsUsage: "file.timeclock|-",
+						Action:    mutating(entryPointImportTimeclock),
+						Flags:     importFlags("records without an account"),
+					},
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "Export flex entries to other formats",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "ics",
+						Usage:  "Write entries as an iCalendar feed, with an all-day event per entry",
+						Action: entryPointExportICS,
+						Before: applyDurationFormat,
+						Flags:  exportFlags(),
+					},
+					{
+						Name:   "timeclock",
+						Usage:  "Write entries as ledger/hledger timeclock records, clocking out after the time worked",
+						Action: entryPointExportTimeclock,
+						Before: applyDurationFormat,
+						Flags: append(
+							exportFlags(),
+							&cli.StringFlag{
+								Name:  "account",
+								Value: "work",
+								Usage: "`ACCOUNT` to clock in to, the customer name is added as the last part",
+							},
+							&cli.StringFlag{
+								Name:  "start",
+								Value: "08:00",
+								Usage: "Time (`HH:MM`) to clock in at, as entries have no times",
+							},
+						),
+					},
+				},
+			},
+			{
+				Name:      "comment",
+				Usage:     "Set or append to the comment of an existing entry",
+				ArgsUsage: "text",
+				Action:    mutating(entryPointComment),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer `name` the entry belongs to",
+					},
+					&cli.StringFlag{
+						Name:    "date",
+						Aliases: []string{"d"},
+						Usage:   "`DATE` of the entry",
+					},
+					&cli.BoolFlag{
+						Name:    "append",
+						Aliases: []string{"a"},
+						Usage:   "Append to the existing comment instead of replacing it",
+					},
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "The `ID` of the entry, needed if there are several entries for the date",
 					},
 				},
 			},
@@ -139,6 +689,7 @@ func main() {
 				Aliases:                []string{"ls"},
 				Usage:                  "List recorded flex time",
 				Action:                 entryPointList,
+				Before:                 applyDurationFormat,
 				UseShortOptionHandling: true,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
@@ -170,31 +721,179 @@ func main() {
 							entrySortOrderOptions(),
 						),
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "date",
 						Aliases: []string{"d"},
-						Usage:   "List entries for this specific date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "List entries for this specific `DATE`",
+					},
+					&cli.StringFlag{
+						Name:    "from",
+						Aliases: []string{"f"},
+						Usage:   "List entries starting from this `DATE`",
+					},
+					&cli.StringFlag{
+						Name:    "to",
+						Aliases: []string{"t"},
+						Usage:   "List entries up to this `DATE`",
+					},
+					periodFlag(),
+					&cli.StringFlag{
+						Name:    "grep",
+						Aliases: []string{"g"},
+						Usage:   "Only list entries with a comment matching this regular `expression`",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "text",
+						Usage:   fmt.Sprintf("Output `format` (options: %s). All but text list every entry", outputFormatOptions()),
+					},
+					&cli.StringFlag{
+						Name:    "template",
+						Aliases: []string{"T"},
+						Usage:   "Format the listing with this Go text/template `file`",
+					},
+					durationFormatFlag(),
+				},
+			},
+			{
+				Name:   "report",
+				Usage:  "Show flex per day, week, month, quarter or year, with running balance",
+				Action: entryPointReport,
+				Before: applyDurationFormat,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer to report flex time for",
+					},
+					&cli.BoolFlag{
+						Name:    "all",
+						Aliases: []string{"a"},
+						Usage:   "Report flex for all customers",
+					},
+					&cli.StringFlag{
+						Name: "customer-sort",
+						Usage: fmt.Sprintf(
+							"Sort `order` if reporting all customers. (options: %s)",
+							customerSortOrderOptions(),
+						),
+					},
+					&cli.StringFlag{
+						Name:    "group-by",
+						Aliases: []string{"G"},
+						Value:   flex.PeriodWeek.String(),
+						Usage:   fmt.Sprintf("Sum flex per `period` (options: %s)", flex.PeriodOptions()),
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "from",
 						Aliases: []string{"f"},
-						Usage:   "List entries starting from this date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "Report entries starting from this `DATE`. The balance includes earlier entries",
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "to",
 						Aliases: []string{"t"},
-						Usage:   "List entries up to this date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "Report entries up to this `DATE`",
+					},
+					periodFlag(),
+					&cli.StringFlag{
+						Name:    "grep",
+						Aliases: []string{"g"},
+						Usage:   "Only include entries with a comment matching this regular `expression`",
+					},
+					&cli.BoolFlag{
+						Name:    "empty",
+						Aliases: []string{"e"},
+						Usage:   "Also show periods without entries",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "text",
+						Usage:   fmt.Sprintf("Output `format` (options: %s)", outputFormatOptions()),
 					},
+					&cli.StringFlag{
+						Name:    "template",
+						Aliases: []string{"T"},
+						Usage:   "Format the report with this Go text/template `file`",
+					},
+					durationFormatFlag(),
 				},
 			},
+			{
+				Name:   "chart",
+				Usage:  "Draw flex per week or month, and the running balance, in the terminal",
+				Action: entryPointChart,
+				Before: applyDurationFormat,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "customer",
+						Aliases: []string{"c"},
+						Usage:   "The customer to chart flex time for",
+					},
+					&cli.BoolFlag{
+						Name:    "all",
+						Aliases: []string{"a"},
+						Usage:   "Chart flex for all customers",
+					},
+					&cli.StringFlag{
+						Name: "customer-sort",
+						Usage: fmt.Sprintf(
+							"Sort `order` if charting all customers. (options: %s)",
+							customerSortOrderOptions(),
+						),
+					},
+					&cli.StringFlag{
+						Name:    "group-by",
+						Aliases: []string{"G"},
+						Value:   flex.PeriodWeek.String(),
+						Usage:   fmt.Sprintf("Sum flex per `period` (options: %s)", flex.PeriodOptions()),
+					},
+					&cli.StringFlag{
+						Name:    "from",
+						Aliases: []string{"f"},
+						Usage:   "Chart entries starting from this `DATE`. The balance includes earlier entries",
+					},
+					&cli.StringFlag{
+						Name:    "to",
+						Aliases: []string{"t"},
+						Usage:   "Chart entries up to this `DATE`",
+					},
+					periodFlag(),
+					&cli.StringFlag{
+						Name:    "grep",
+						Aliases: []string{"g"},
+						Usage:   "Only include entries with a comment matching this regular `expression`",
+					},
+					&cli.IntFlag{
+						Name:    "width",
+						Aliases: []string{"w"},
+						Usage:   "Width in `columns`, instead of the terminal width",
+					},
+					&cli.BoolFlag{
+						Name:  "ascii",
+						Usage: "Draw with ASCII characters only. This is the default when not writing to a terminal",
+					},
+					durationFormatFlag(),
+				},
+			},
+			{
+				Name:   "move",
+				Usage:  "Move entries from one customer to another",
+				Action: mutating(entryPointMove),
+				Flags:  transferFlags("Move"),
+			},
+			{
+				Name:   "copy",
+				Usage:  "Copy entries from one customer to another",
+				Action: mutating(entryPointCopy),
+				Flags:  transferFlags("Copy"),
+			},
 			{
 				Name:    "delete",
 				Aliases: []string{"del", "rm"},
 				Usage:   "Delete flex entries",
-				Action:  entryPointDelete,
+				Action:  mutating(entryPointDelete),
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    "customer",
@@ -206,23 +905,95 @@ func main() {
 						Aliases: []string{"a"},
 						Usage:   "Delete matching entries from all customers, or all entries from matching customer",
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "date",
 						Aliases: []string{"d"},
-						Usage:   "Delete entries matching this specific date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "Delete entries matching this specific `DATE`",
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "Delete the entry with this `ID`",
+					},
+					&cli.StringFlag{
 						Name:    "from",
 						Aliases: []string{"f"},
-						Usage:   "Delete entries starting from this date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "Delete entries starting from this `DATE`",
 					},
-					&cli.TimestampFlag{
+					&cli.StringFlag{
 						Name:    "to",
 						Aliases: []string{"t"},
-						Usage:   "Delete entries up to this date",
-						Layout:  flex.ShortDateFormat,
+						Usage:   "Delete entries up to this `DATE`",
+					},
+					periodFlag(),
+					&cli.BoolFlag{
+						Name:    "dry-run",
+						Aliases: []string{"n"},
+						Usage:   "Only show what would be deleted",
+					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Delete without asking, also needed when stdin is not a terminal",
+					},
+				},
+			},
+			{
+				Name:   "undo",
+				Usage:  "Revert the last change recorded in the journal",
+				Action: withLock(entryPointUndo),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Revert even if the DB was changed outside of the journal since",
+					},
+				},
+			},
+			{
+				Name:   "redo",
+				Usage:  "Apply the last undone change again",
+				Action: withLock(entryPointRedo),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Apply even if the DB was changed outside of the journal since",
+					},
+				},
+			},
+			{
+				Name:   "migrate",
+				Usage:  fmt.Sprintf("Upgrade the DB file to the current schema version (%d)", flex.SchemaVersion),
+				Action: mutating(entryPointMigrate),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Only report what would change, without saving",
+					},
+				},
+			},
+			{
+				Name:   "doctor",
+				Usage:  "Check the DB file for problems, like duplicates, empty entries and unknown fields",
+				Action: mutating(entryPointDoctor),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "fix",
+						Usage: "Fix what can be fixed and save, reporting what was done",
+					},
+					&cli.BoolFlag{
+						Name:  "fix-duplicates",
+						Usage: "Also drop entries alike in all but ID, which --fix leaves as they may be on purpose. Implies --fix",
+					},
+				},
+			},
+			{
+				Name:   "history",
+				Usage:  "Show the changes recorded in the journal",
+				Action: entryPointHistory,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:    "limit",
+						Aliases: []string{"n"},
+						Usage:   "Only show the last `N` records",
 					},
 				},
 			},
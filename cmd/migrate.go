@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointMigrate(c *cli.Context) error {
+	log.Debug().Msg("In entryPointMigrate")
+
+	fileName := c.String("file")
+	check := c.Bool("check")
+
+	if fileName == "" {
+		return fmt.Errorf("%w: no file to migrate", ErrInvalidOptionCombination)
+	}
+
+	file, err := flex.GetFileOrStdinForReading(fileName)
+	if err != nil {
+		return err
+	}
+	data, report, err := flex.MigrateJSON(file)
+	if fileName != "-" {
+		file.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	if check {
+		writeMigrationReport(os.Stdout, fileName, report, "would migrate")
+		return nil
+	}
+	if !report.NeedsMigration() {
+		writeMigrationReport(os.Stdout, fileName, report, "")
+		return nil
+	}
+
+	// The migrated data is decoded, rather than reading the file again, as it may be stdin
+	db, err := flex.DecodeDB(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	db.FileName = fileName
+	if err = saveDB(db); err != nil {
+		return err
+	}
+	writeMigrationReport(reportWriter(db), fileName, report, "migrated")
+
+	return nil
+}
+
+// writeMigrationReport writes what a migration did, or would do, as told by verb
+func writeMigrationReport(writer io.Writer, fileName string, report flex.MigrationReport, verb string) {
+	if !report.NeedsMigration() {
+		fmt.Fprintf(writer, "%s: schema version %d, up to date\n", fileName, report.From)
+		return
+	}
+	fmt.Fprintf(writer, "%s: schema version %d, %s to %d:\n", fileName, report.From, verb, report.To)
+	for _, change := range report.Changes {
+		fmt.Fprintf(writer, "\t%s\n", change)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+)
+
+type outputFormat uint8
+
+const (
+	outputText outputFormat = iota
+	outputJSON
+	outputCSV
+	outputTSV
+)
+
+var outputFormats = map[string]outputFormat{
+	"text": outputText,
+	"json": outputJSON,
+	"csv":  outputCSV,
+	"tsv":  outputTSV,
+}
+
+func outputFormatOptions() string {
+	keys := make([]string, 0, len(outputFormats))
+	for key := range outputFormats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+func parseOutputFormat(name string) (outputFormat, error) {
+	if name == "" {
+		return outputText, nil
+	}
+	format, ok := outputFormats[strings.ToLower(name)]
+	if !ok {
+		return outputText, fmt.Errorf("%w: %q (options: %s)", ErrInvalidOutputFormat, name, outputFormatOptions())
+	}
+	return format, nil
+}
+
+// formatMinutes returns the given duration as minutes, for output meant to be read by other programs
+func formatMinutes(d time.Duration) string {
+	return strconv.FormatFloat(d.Minutes(), 'f', -1, 64)
+}
+
+// jsonEntry is how an entry is written by list --output json.
+// Amounts are given both as minutes and as a Go duration string.
+type jsonEntry struct {
+	ID            string  `json:"id,omitempty"`
+	Date          string  `json:"date"`
+	Amount        string  `json:"amount"`
+	AmountMinutes float64 `json:"amount_minutes"`
+	WorkedMinutes float64 `json:"worked_minutes,omitempty"`
+	// BalanceMinutes is the balance at the end of the date, including all entries up to then
+	BalanceMinutes float64 `json:"balance_minutes"`
+	Comment        string  `json:"comment,omitempty"`
+	DayOff         string  `json:"day_off,omitempty"`
+}
+
+type jsonCustomer struct {
+	Name         string      `json:"name"`
+	Total        string      `json:"total"`
+	TotalMinutes float64     `json:"total_minutes"`
+	DaysOff      int         `json:"days_off,omitempty"`
+	Entries      []jsonEntry `json:"entries"`
+}
+
+type jsonListing struct {
+	Customers    []jsonCustomer `json:"customers"`
+	Total        string         `json:"total"`
+	TotalMinutes float64        `json:"total_minutes"`
+}
+
+func writeJSONListings(writer io.Writer, calendar flex.Calendar, listings []*customerListing) error {
+	output := jsonListing{Customers: make([]jsonCustomer, 0, len(listings))}
+	var total time.Duration
+	for _, listing := range listings {
+		customerTotal := listing.entries.GetTotalFlex()
+		total += customerTotal
+		customer := jsonCustomer{
+			Name:         listing.customer.Name,
+			Total:        customerTotal.String(),
+			TotalMinutes: customerTotal.Minutes(),
+			DaysOff:      listing.daysOff.Len(),
+			Entries:      make([]jsonEntry, 0, listing.entries.Len()),
+		}
+		for _, entry := range listing.entries {
+			jEntry := jsonEntry{
+				ID:             entry.ID,
+				Date:           entry.Date.Format(flex.ShortDateFormat),
+				Amount:         entry.Amount.String(),
+				AmountMinutes:  entry.Amount.Minutes(),
+				WorkedMinutes:  entry.Worked.Minutes(),
+				BalanceMinutes: listing.history.At(entry.Date).Minutes(),
+				Comment:        entry.Comment,
+			}
+			if day := calendar.Get(entry.Date); day != nil {
+				jEntry.DayOff = day.String()
+			}
+			customer.Entries = append(customer.Entries, jEntry)
+		}
+		output.Customers = append(output.Customers, customer)
+	}
+	output.Total = total.String()
+	output.TotalMinutes = total.Minutes()
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// writeDelimitedListings writes one row per entry, with a header, separated by the given delimiter
+func writeDelimitedListings(writer io.Writer, delimiter rune, listings []*customerListing) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = delimiter
+	if err := csvWriter.Write([]string{"customer", "date", "amount_minutes", "comment"}); err != nil {
+		return err
+	}
+	for _, listing := range listings {
+		for _, entry := range listing.entries {
+			err := csvWriter.Write([]string{
+				listing.customer.Name,
+				entry.Date.Format(flex.ShortDateFormat),
+				formatMinutes(entry.Amount),
+				entry.Comment,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
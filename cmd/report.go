@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// reportCustomer is the report for one customer, as given to templates
+type reportCustomer struct {
+	Name     string
+	Customer *flex.Customer
+	Periods  flex.PeriodTotals
+	Opening  time.Duration // balance from entries before the reported range
+	Count    int
+	Total    time.Duration // sum of the reported periods
+	Balance  time.Duration // balance at the end of the last period
+}
+
+// reportData is the data given to report templates
+type reportData struct {
+	DB        *flex.DB
+	GroupBy   flex.Period
+	Customers []reportCustomer
+	Now       time.Time
+}
+
+func entryPointReport(c *cli.Context) error {
+	log.Debug().Msg("In entryPointReport")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	all := c.Bool("all")
+	customerSort := stringFlag(c, "customer-sort", config.CustomerSort)
+	groupBy := c.String("group-by")
+	grep := c.String("grep")
+	empty := c.Bool("empty")
+	templateFile := c.String("template")
+	output := c.String("output")
+	if templateFile == "" {
+		// A configured output format is only used without a template, as they can't be combined
+		output = stringFlag(c, "output", config.Output)
+	}
+
+	log.Debug().
+		Str("FileName", fileName).
+		Str("CustomerName", customerName).
+		Bool("All", all).
+		Str("GroupBy", groupBy).
+		Bool("Empty", empty).
+		Str("Output", output).
+		Str("Template", templateFile).
+		Send()
+
+	period, err := flex.ParsePeriod(groupBy)
+	if err != nil {
+		return err
+	}
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+	format, err := parseOutputFormat(output)
+	if err != nil {
+		return err
+	}
+	if templateFile != "" && format != outputText {
+		return fmt.Errorf("%w: template and output", ErrInvalidOptionCombination)
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	var customer *flex.Customer
+	if customerName != "" {
+		customer, err = db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+	}
+	customerSortValue := flex.CustomerNoSort
+	if value, ok := customerSortOrder[customerSort]; ok {
+		customerSortValue = value
+	}
+	customers := selectCustomers(db, customer, all, customerSortValue)
+	if customers == nil {
+		return fmt.Errorf("%w: either customer or all is needed", ErrInvalidOptionCombination)
+	}
+
+	var commentExpr *regexp.Regexp
+	if grep != "" {
+		commentExpr, err = regexp.Compile(grep)
+		if err != nil {
+			return err
+		}
+	}
+
+	listings, err := selectListings(db, customers, customer != nil, nil, from, to, commentExpr)
+	if err != nil {
+		return err
+	}
+
+	data := reportData{
+		DB:        db,
+		GroupBy:   period,
+		Customers: make([]reportCustomer, 0, len(listings)),
+		Now:       time.Now(),
+	}
+	for _, listing := range listings {
+		data.Customers = append(data.Customers, newReportCustomer(listing, period, from, empty))
+	}
+
+	if templateFile != "" {
+		tmpl, err := loadTemplate(templateFile, db.Calendar)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(os.Stdout, data)
+	}
+
+	switch format {
+	case outputJSON:
+		return writeJSONReport(os.Stdout, data)
+	case outputCSV:
+		return writeDelimitedReport(os.Stdout, ',', data)
+	case outputTSV:
+		return writeDelimitedReport(os.Stdout, '\t', data)
+	}
+
+	builder := strings.Builder{}
+	writeReport(&builder, data)
+	fmt.Print(builder.String())
+
+	return nil
+}
+
+// newReportCustomer groups the entries of the listing by period. If from is given, the running balance
+// starts from the sum of the customers entries before that date.
+func newReportCustomer(listing *customerListing, period flex.Period, from *time.Time, empty bool) reportCustomer {
+	var opening time.Duration
+	if from != nil {
+		opening = listing.customer.Entries.BalanceBefore(*from)
+	}
+	periods := listing.entries.GroupByPeriod(period, opening)
+	if empty {
+		periods = periods.Fill()
+	}
+	return reportCustomer{
+		Name:     listing.customer.Name,
+		Customer: listing.customer,
+		Periods:  periods,
+		Opening:  opening,
+		Count:    periods.Count(),
+		Total:    periods.Amount(),
+		Balance:  opening + periods.Amount(),
+	}
+}
+
+const reportLineFormat = "\t%-10s %7v %12s %12s\n"
+
+func writeReport(writer io.Writer, data reportData) {
+	for _, customer := range data.Customers {
+		fmt.Fprintf(writer, "%s: %s (by %s)\n", customer.Name, formatDuration(customer.Total), data.GroupBy)
+		fmt.Fprintf(writer, reportLineFormat, data.GroupBy, "entries", "flex", "balance")
+		if customer.Opening != 0 {
+			fmt.Fprintf(writer, reportLineFormat, "opening", "", "", formatDuration(customer.Opening))
+		}
+		for _, total := range customer.Periods {
+			fmt.Fprintf(
+				writer,
+				reportLineFormat,
+				total.Label(),
+				total.Count,
+				formatDuration(total.Amount),
+				formatDuration(total.Balance),
+			)
+		}
+		fmt.Fprintf(
+			writer,
+			reportLineFormat,
+			"total",
+			customer.Count,
+			formatDuration(customer.Total),
+			formatDuration(customer.Balance),
+		)
+	}
+}
+
+type jsonPeriod struct {
+	Period         string  `json:"period"`
+	Start          string  `json:"start"`
+	End            string  `json:"end"` // last date within the period
+	Count          int     `json:"count"`
+	Amount         string  `json:"amount"`
+	AmountMinutes  float64 `json:"amount_minutes"`
+	Balance        string  `json:"balance"`
+	BalanceMinutes float64 `json:"balance_minutes"`
+}
+
+type jsonReportCustomer struct {
+	Name           string       `json:"name"`
+	OpeningMinutes float64      `json:"opening_minutes"`
+	Count          int          `json:"count"`
+	TotalMinutes   float64      `json:"total_minutes"`
+	BalanceMinutes float64      `json:"balance_minutes"`
+	Periods        []jsonPeriod `json:"periods"`
+}
+
+type jsonReport struct {
+	GroupBy   string               `json:"group_by"`
+	Customers []jsonReportCustomer `json:"customers"`
+}
+
+func writeJSONReport(writer io.Writer, data reportData) error {
+	output := jsonReport{
+		GroupBy:   data.GroupBy.String(),
+		Customers: make([]jsonReportCustomer, 0, len(data.Customers)),
+	}
+	for _, customer := range data.Customers {
+		jCustomer := jsonReportCustomer{
+			Name:           customer.Name,
+			OpeningMinutes: customer.Opening.Minutes(),
+			Count:          customer.Count,
+			TotalMinutes:   customer.Total.Minutes(),
+			BalanceMinutes: customer.Balance.Minutes(),
+			Periods:        make([]jsonPeriod, 0, len(customer.Periods)),
+		}
+		for _, total := range customer.Periods {
+			jCustomer.Periods = append(jCustomer.Periods, jsonPeriod{
+				Period:         total.Label(),
+				Start:          total.Start.Format(flex.ShortDateFormat),
+				End:            total.End.AddDate(0, 0, -1).Format(flex.ShortDateFormat),
+				Count:          total.Count,
+				Amount:         total.Amount.String(),
+				AmountMinutes:  total.Amount.Minutes(),
+				Balance:        total.Balance.String(),
+				BalanceMinutes: total.Balance.Minutes(),
+			})
+		}
+		output.Customers = append(output.Customers, jCustomer)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// writeDelimitedReport writes one row per customer and period, with a header, separated by the given delimiter
+func writeDelimitedReport(writer io.Writer, delimiter rune, data reportData) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = delimiter
+	err := csvWriter.Write([]string{"customer", "period", "start", "count", "amount_minutes", "balance_minutes"})
+	if err != nil {
+		return err
+	}
+	for _, customer := range data.Customers {
+		for _, total := range customer.Periods {
+			err = csvWriter.Write([]string{
+				customer.Name,
+				total.Label(),
+				total.Start.Format(flex.ShortDateFormat),
+				strconv.Itoa(total.Count),
+				formatMinutes(total.Amount),
+				formatMinutes(total.Balance),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointScheduleShow(c *cli.Context) error {
+	log.Debug().Msg("In entryPointScheduleShow")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	useDefault := c.Bool("default")
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	var customer *flex.Customer
+	if customerName != "" && !useDefault {
+		customer, err = db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+	}
+
+	source := "standard"
+	switch {
+	case customer != nil && customer.Schedule != nil:
+		source = customer.Name
+	case db.DefaultSchedule != nil:
+		source = "default"
+	}
+
+	schedule := db.ScheduleFor(customer)
+	fmt.Printf("Schedule (%s): %s/week\n", source, formatDuration(schedule.Weekly()))
+	for _, weekday := range flex.Weekdays {
+		fmt.Printf("\t* %-9s: %s\n", weekday, formatDuration(schedule.Expected(weekday)))
+	}
+
+	return nil
+}
+
+func entryPointScheduleSet(c *cli.Context) error {
+	log.Debug().Msg("In entryPointScheduleSet")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	useDefault := c.Bool("default")
+	spec := strings.Join(c.Args().Slice(), ",")
+
+	if (customerName != "") == useDefault {
+		return fmt.Errorf("%w: give either customer or default", ErrInvalidOptionCombination)
+	}
+	if spec == "" {
+		return fmt.Errorf("%w: no schedule given", flex.ErrInvalidSchedule)
+	}
+
+	schedule, err := flex.ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	if useDefault {
+		db.DefaultSchedule = schedule
+	} else {
+		customer, err := db.AddCustomer(customerName)
+		if err != nil {
+			log.Debug().Err(err).Send()
+		}
+		customer.Schedule = schedule
+	}
+
+	log.Info().
+		Str("customer_name", customerName).
+		Bool("default", useDefault).
+		Stringer("schedule", schedule).
+		Msg("Schedule set")
+
+	return saveDB(db)
+}
+
+func entryPointScheduleClear(c *cli.Context) error {
+	log.Debug().Msg("In entryPointScheduleClear")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	useDefault := c.Bool("default")
+
+	if (customerName != "") == useDefault {
+		return fmt.Errorf("%w: give either customer or default", ErrInvalidOptionCombination)
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	if useDefault {
+		db.DefaultSchedule = nil
+	} else {
+		customer, err := db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+		customer.Schedule = nil
+	}
+
+	return saveDB(db)
+}
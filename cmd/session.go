@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// parseClock returns the time of day given as HH:MM on the date of now,
+// or now itself if clock is blank.
+func parseClock(clock string, now time.Time) (time.Time, error) {
+	if clock == "" {
+		return now, nil
+	}
+	t, err := time.ParseInLocation(flex.ClockFormat, clock, now.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := now.Date()
+	return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+func entryPointStart(c *cli.Context) error {
+	log.Debug().Msg("In entryPointStart")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+
+	start, err := parseClock(c.String("at"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", fileName).
+		Str("CustomerName", customerName).
+		Time("Start", start).
+		Send()
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		log.Error().Err(err).Send()
+	}
+
+	customer, err := db.StartSession(customerName, start)
+	if err != nil {
+		return err
+	}
+
+	if err = saveDB(db); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("customer_name", customer.Name).
+		Str("start", start.Format(flex.ClockFormat)).
+		Msg("Started session")
+
+	return nil
+}
+
+func entryPointStop(c *cli.Context) error {
+	log.Debug().Msg("In entryPointStop")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+	overwrite := c.Bool("overwrite")
+
+	stop, err := parseClock(c.String("at"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", fileName).
+		Str("CustomerName", customerName).
+		Time("Stop", stop).
+		Bool("Overwrite", overwrite).
+		Send()
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	customer, entry, err := db.StopSession(customerName, stop, overwrite)
+	if err != nil {
+		return err
+	}
+
+	if err = saveDB(db); err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"%s:\n\t* %s: %s (worked: %s)\n",
+		customer.Name,
+		entry.Date.Format(flex.ShortDateFormat),
+		formatDuration(entry.Amount),
+		formatDuration(entry.Worked),
+	)
+
+	return nil
+}
+
+func entryPointStatus(c *cli.Context) error {
+	log.Debug().Msg("In entryPointStatus")
+
+	fileName := c.String("file")
+	customerName := customerFlag(c)
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	running := db.CustomersWithSession()
+	if customerName != "" {
+		customer, err := db.GetCustomer(customerName)
+		if err != nil {
+			return err
+		}
+		running = flex.Customers{customer}
+	}
+
+	now := time.Now()
+	for _, customer := range running {
+		if !customer.HasSession() {
+			fmt.Printf("%s: no running session\n", customer.Name)
+			continue
+		}
+		fmt.Printf(
+			"%s: running since %s (%s)\n",
+			customer.Name,
+			customer.Session.Start.Format(flex.ShortDateFormat+" "+flex.ClockFormat),
+			formatDuration(customer.Session.Elapsed(now).Truncate(time.Second)),
+		)
+	}
+
+	if running.Len() == 0 {
+		fmt.Println("No running sessions")
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+)
+
+/*
+
+Templates given with --template are Go text/template files, see https://pkg.go.dev/text/template
+
+For list, the data given to the template is a listData:
+	.DB          the whole *flex.DB, e.g. .DB.Calendar or .DB.DefaultSchedule
+	.Customers   the listed customers, each a listCustomer:
+		.Name        the customer name
+		.Customer    the whole *flex.Customer, e.g. .Customer.Schedule
+		.Entries     the listed flex.Entries, after date, range and grep filters, each a *flex.Entry:
+			.ID, .Date (time.Time), .Amount and .Worked (time.Duration), .Comment
+		.Total       sum of the listed entries (time.Duration)
+		.DaysOff     calendar days within the listed range, if a range was given (flex.Calendar)
+		.History     the balance over time of all the customers entries (flex.BalanceHistory), e.g.
+			{{.History.At .Date}} for the balance at the end of a date, or .History.Min and .History.Max
+	.Total       sum of all listed entries (time.Duration)
+	.Now         the current time
+
+For report, the data given to the template is a reportData:
+	.DB          the whole *flex.DB
+	.GroupBy     the flex.Period entries are grouped by, e.g. week
+	.Customers   the reported customers, each a reportCustomer:
+		.Name, .Customer   as for list
+		.Periods     the flex.PeriodTotals, each a flex.PeriodTotal:
+			.Label, .Start and .End (first date after the period), .Count, .Amount, .Balance
+		.Opening     balance from entries before --from (time.Duration)
+		.Count       number of reported entries
+		.Total       sum of the reported periods (time.Duration)
+		.Balance     balance at the end of the last period (time.Duration)
+	.Now         the current time
+
+Functions available in templates, in addition to the text/template builtins:
+	duration D       D formatted like in the text output, per --duration-format, e.g. 1h30m0s
+	clock D          D as signed hours and minutes, e.g. +1:30 or -0:15
+	hours D          D as decimal hours with two decimals, e.g. 1.50
+	minutes D        D as minutes, e.g. 90
+	date T           T as YYYY-MM-DD
+	formatDate L T   T formatted with the Go time layout L, e.g. formatDate "Jan 2" .Date
+	total ENTRIES    sum of the amounts of ENTRIES
+	dayOff T         the calendar day for T as text, or an empty string
+	latex S          S with LaTeX special characters escaped
+	markdown S       S with Markdown table special characters escaped
+	replace S O N    S with all O replaced by N
+	upper S, lower S
+
+Example, a Markdown table:
+	| Customer | Flex |
+	|---|---|
+	{{range .Customers}}| {{markdown .Name}} | {{clock .Total}} |
+	{{end}}
+
+*/
+
+// listCustomer is a customer along with what was selected for listing, as given to templates
+type listCustomer struct {
+	Name     string
+	Customer *flex.Customer
+	Entries  flex.Entries
+	Total    time.Duration
+	DaysOff  flex.Calendar
+	History  flex.BalanceHistory
+}
+
+// listData is the data given to list templates
+type listData struct {
+	DB        *flex.DB
+	Customers []listCustomer
+	Total     time.Duration
+	Now       time.Time
+}
+
+// formatClock returns the duration as signed hours and minutes, e.g. "+1:30"
+func formatClock(d time.Duration) string {
+	return flex.DurationFormatClock.Format(d, 0)
+}
+
+func templateFuncs(calendar flex.Calendar) template.FuncMap {
+	return template.FuncMap{
+		"duration": formatDuration,
+		"clock":    formatClock,
+		"hours":    func(d time.Duration) string { return fmt.Sprintf("%.2f", d.Hours()) },
+		"minutes":  func(d time.Duration) float64 { return math.Round(d.Minutes()*100) / 100 },
+		"date":     func(t time.Time) string { return t.Format(flex.ShortDateFormat) },
+		"formatDate": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"total": func(entries flex.Entries) time.Duration { return entries.GetTotalFlex() },
+		"dayOff": func(t time.Time) string {
+			if day := calendar.Get(t); day != nil {
+				return day.String()
+			}
+			return ""
+		},
+		"latex": strings.NewReplacer(
+			`\`, `\textbackslash{}`,
+			`&`, `\&`,
+			`%`, `\%`,
+			`$`, `\$`,
+			`#`, `\#`,
+			`_`, `\_`,
+			`{`, `\{`,
+			`}`, `\}`,
+			`~`, `\textasciitilde{}`,
+			`^`, `\textasciicircum{}`,
+		).Replace,
+		"markdown": strings.NewReplacer(
+			`\`, `\\`,
+			`|`, `\|`,
+			`*`, `\*`,
+			`_`, `\_`,
+			"`", "\\`",
+			"\n", " ",
+		).Replace,
+		"replace": strings.ReplaceAll,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+	}
+}
+
+// loadTemplate parses the given template file, with the functions from templateFuncs
+func loadTemplate(fileName string, calendar flex.Calendar) (*template.Template, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(fileName)).
+		Funcs(templateFuncs(calendar)).
+		Option("missingkey=error").
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func newListData(db *flex.DB, listings []*customerListing) listData {
+	data := listData{
+		DB:        db,
+		Customers: make([]listCustomer, 0, len(listings)),
+		Now:       time.Now(),
+	}
+	for _, listing := range listings {
+		total := listing.entries.GetTotalFlex()
+		data.Customers = append(data.Customers, listCustomer{
+			Name:     listing.customer.Name,
+			Customer: listing.customer,
+			Entries:  listing.entries,
+			Total:    total,
+			DaysOff:  listing.daysOff,
+			History:  listing.history,
+		})
+		data.Total += total
+	}
+	return data
+}
+
+func writeTemplateListings(writer io.Writer, fileName string, db *flex.DB, listings []*customerListing) error {
+	tmpl, err := loadTemplate(fileName, db.Calendar)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, newListData(db, listings))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mattn/go-isatty"
+)
+
+// defaultTerminalWidth is used when the width can not be found from $COLUMNS or the terminal
+const defaultTerminalWidth = 80
+
+// isTerminal returns true if the given file is a terminal
+func isTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
+
+// terminalWidth returns the number of columns available for output to the given file,
+// from $COLUMNS if set, or else from the terminal if it is one.
+func terminalWidth(file *os.File) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if isTerminal(file) {
+		if width := ttyWidth(file); width > 0 {
+			return width
+		}
+	}
+	return defaultTerminalWidth
+}
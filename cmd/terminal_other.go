@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// ttyWidth is only implemented for unix like systems, elsewhere $COLUMNS or the default is used
+func ttyWidth(file *os.File) int {
+	return 0
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+func entryPointMove(c *cli.Context) error {
+	log.Debug().Msg("In entryPointMove")
+	return transferEntries(c, true)
+}
+
+func entryPointCopy(c *cli.Context) error {
+	log.Debug().Msg("In entryPointCopy")
+	return transferEntries(c, false)
+}
+
+// transferEntries moves or copies the selected entries between customers, for move and copy
+func transferEntries(c *cli.Context, move bool) error {
+	fileName := c.String("file")
+	sourceName := c.String("from-customer")
+	targetName := c.String("to-customer")
+	id := c.String("id")
+	all := c.Bool("all")
+	dryRun := c.Bool("dry-run")
+
+	if sourceName == "" || targetName == "" {
+		return fmt.Errorf("%w: both from-customer and to-customer are needed", ErrInvalidOptionCombination)
+	}
+	policy, err := flex.ParseTransferPolicy(c.String("policy"))
+	if err != nil {
+		return err
+	}
+	date, err := dateFlag(c, "date")
+	if err != nil {
+		return err
+	}
+	from, to, err := rangeFlags(c)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return err
+		}
+		if db.IsEmpty() {
+			return flex.ErrEmptyDB
+		}
+	}
+
+	source, err := db.GetCustomer(sourceName)
+	if err != nil {
+		return err
+	}
+	// The target is added if it doesn't exist, like for add
+	target, err := db.AddCustomer(targetName)
+	if err != nil {
+		log.Debug().Err(err).Send()
+	}
+
+	entries, err := selectTransferEntries(source, all, id, date, from, to)
+	if err != nil {
+		return err
+	}
+	if entries.Len() == 0 {
+		return fmt.Errorf("%w: nothing selected from %s", flex.ErrNoEntry, source.Name)
+	}
+
+	result, err := db.TransferEntries(source, target, entries, policy, move)
+	if err != nil {
+		return err
+	}
+
+	verb, dryRunVerb := "Copied", "Would copy"
+	if move {
+		verb, dryRunVerb = "Moved", "Would move"
+	}
+	if dryRun {
+		writeTransferResult(os.Stdout, dryRunVerb, db.Calendar, source, target, result)
+		return nil
+	}
+
+	if err = saveDB(db); err != nil {
+		return err
+	}
+	writeTransferResult(reportWriter(db), verb, db.Calendar, source, target, result)
+
+	return nil
+}
+
+// selectTransferEntries returns the entries of the customer selected by ID, a single date or a date range,
+// with the same meaning as for delete, or all entries if all is true and none of them is given
+func selectTransferEntries(customer *flex.Customer, all bool, id string, date, from, to *time.Time) (flex.Entries, error) {
+	if all && (id != "" || date != nil || from != nil || to != nil) {
+		return nil, fmt.Errorf("%w: all with id, date or range", ErrInvalidOptionCombination)
+	}
+	switch {
+	case all:
+		return append(flex.Entries(nil), customer.Entries...), nil
+	case id != "":
+		if date != nil || from != nil || to != nil {
+			return nil, fmt.Errorf("%w: id with date or range", ErrInvalidOptionCombination)
+		}
+		entry, err := customer.GetEntryByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return flex.Entries{entry}, nil
+	case date != nil:
+		if from != nil || to != nil {
+			return nil, fmt.Errorf("%w: date with range", ErrInvalidOptionCombination)
+		}
+		return customer.GetEntries(*date)
+	case from != nil || to != nil:
+		first, last, err := dateRange(customer, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return customer.Entries.FilterByDateRange(first, last), nil
+	default:
+		return nil, fmt.Errorf("%w: give id, date, a range or all", ErrInvalidOptionCombination)
+	}
+}
+
+func writeTransferResult(
+	writer io.Writer,
+	verb string,
+	calendar flex.Calendar,
+	source, target *flex.Customer,
+	result flex.TransferResult,
+) {
+	fmt.Fprintf(
+		writer,
+		"%s %s, %s flex, from %s to %s\n",
+		verb,
+		countEntries(result.Transferred.Len()),
+		formatDuration(result.Transferred.GetTotalFlex()),
+		source.Name,
+		target.Name,
+	)
+	for _, entry := range result.Transferred {
+		writeEntry(writer, calendar, entry)
+	}
+	if result.Replaced.Len() > 0 {
+		fmt.Fprintf(writer, "Replaced %s of %s:\n", countEntries(result.Replaced.Len()), target.Name)
+		for _, entry := range result.Replaced {
+			writeEntry(writer, calendar, entry)
+		}
+	}
+	if result.Skipped.Len() > 0 {
+		fmt.Fprintf(writer, "Skipped %s, as %s has entries for the same dates:\n", countEntries(result.Skipped.Len()), target.Name)
+		for _, entry := range result.Skipped {
+			writeEntry(writer, calendar, entry)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package flex
+
+import (
+	"sort"
+	"time"
+)
+
+// A BalancePoint is the flex balance at the end of a date with entries
+type BalancePoint struct {
+	Date    time.Time
+	Amount  time.Duration // sum of the entries on the date
+	Balance time.Duration // sum of all entries up to and including the date
+}
+
+// BalanceHistory is the balance over time, sorted by date
+type BalanceHistory []BalancePoint
+
+// BalanceHistory returns the running balance for each date with entries, within from and to, inclusive.
+// Either of from and to may be nil for an open range. Entries before from are included in the balance,
+// but get no points of their own.
+func (entries Entries) BalanceHistory(from, to *time.Time) BalanceHistory {
+	amounts := make(map[time.Time]time.Duration)
+	for _, entry := range entries {
+		amounts[DateOf(entry.Date)] += entry.Amount
+	}
+	dates := make([]time.Time, 0, len(amounts))
+	for date := range amounts {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	history := make(BalanceHistory, 0, len(dates))
+	var balance time.Duration
+	for _, date := range dates {
+		balance += amounts[date]
+		if (from != nil && date.Before(*from)) || (to != nil && date.After(*to)) {
+			continue
+		}
+		history = append(history, BalancePoint{Date: date, Amount: amounts[date], Balance: balance})
+	}
+	return history
+}
+
+// BalanceHistory returns the running balance of the customer, see Entries.BalanceHistory
+func (customer *Customer) BalanceHistory(from, to *time.Time) BalanceHistory {
+	return customer.Entries.BalanceHistory(from, to)
+}
+
+// At returns the balance at the end of the given date, which is the balance of the last point
+// on or before the date, or 0 if there is none. For a history made with a from date,
+// this is only right for dates from then on.
+func (history BalanceHistory) At(date time.Time) time.Duration {
+	idx := sort.Search(len(history), func(i int) bool {
+		return history[i].Date.After(date)
+	})
+	if idx == 0 {
+		return 0
+	}
+	return history[idx-1].Balance
+}
+
+// Min returns the point with the lowest balance, i.e. the deepest in minus.
+// Returns nil if the history is empty.
+func (history BalanceHistory) Min() *BalancePoint {
+	if len(history) == 0 {
+		return nil
+	}
+	lowest := history[0]
+	for _, point := range history[1:] {
+		if point.Balance < lowest.Balance {
+			lowest = point
+		}
+	}
+	return &lowest
+}
+
+// Max returns the point with the highest balance. Returns nil if the history is empty.
+func (history BalanceHistory) Max() *BalancePoint {
+	if len(history) == 0 {
+		return nil
+	}
+	highest := history[0]
+	for _, point := range history[1:] {
+		if point.Balance > highest.Balance {
+			highest = point
+		}
+	}
+	return &highest
+}
@@ -0,0 +1,49 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntriesBalanceHistory(t *testing.T) {
+	entries := Entries{
+		{Date: ymd(2022, 1, 5), Amount: -3 * time.Hour},
+		{Date: ymd(2022, 1, 3), Amount: time.Hour},
+		{Date: ymd(2022, 1, 3), Amount: 30 * time.Minute},
+		{Date: ymd(2022, 1, 10), Amount: 4 * time.Hour},
+		{Date: ymd(2022, 1, 12), Amount: -time.Hour},
+	}
+
+	history := entries.BalanceHistory(nil, nil)
+	if assert.Len(t, history, 4) {
+		assert.Equal(t, BalancePoint{Date: ymd(2022, 1, 3), Amount: 90 * time.Minute, Balance: 90 * time.Minute}, history[0])
+		assert.Equal(t, -90*time.Minute, history[1].Balance)
+		assert.Equal(t, 150*time.Minute, history[2].Balance)
+		assert.Equal(t, 90*time.Minute, history[3].Balance)
+	}
+
+	if lowest := history.Min(); assert.NotNil(t, lowest) {
+		assert.Equal(t, ymd(2022, 1, 5), lowest.Date)
+	}
+	if highest := history.Max(); assert.NotNil(t, highest) {
+		assert.Equal(t, ymd(2022, 1, 10), highest.Date)
+	}
+
+	assert.Equal(t, time.Duration(0), history.At(ymd(2022, 1, 2)))
+	assert.Equal(t, -90*time.Minute, history.At(ymd(2022, 1, 5)))
+	assert.Equal(t, -90*time.Minute, history.At(ymd(2022, 1, 9)))
+	assert.Equal(t, 90*time.Minute, history.At(ymd(2022, 2, 1)))
+
+	// Entries before from count towards the balance, but are not included
+	from, to := ymd(2022, 1, 4), ymd(2022, 1, 10)
+	history = entries.BalanceHistory(&from, &to)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, -90*time.Minute, history[0].Balance)
+		assert.Equal(t, 150*time.Minute, history[1].Balance)
+	}
+
+	assert.Nil(t, BalanceHistory{}.Min())
+	assert.Nil(t, BalanceHistory{}.Max())
+}
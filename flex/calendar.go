@@ -0,0 +1,184 @@
+package flex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DayKind tells why a CalendarDay differs from the normal schedule
+type DayKind uint8
+
+const (
+	DayHoliday DayKind = iota
+	DayVacation
+	DaySickLeave
+	DayAbsence
+)
+
+var dayKindNames = map[DayKind]string{
+	DayHoliday:   "holiday",
+	DayVacation:  "vacation",
+	DaySickLeave: "sick",
+	DayAbsence:   "absence",
+}
+
+// A CalendarDay is a date where the expected work differs from the schedule,
+// like a public holiday, vacation or sick leave.
+// If Expected is nil, no work is expected that day.
+type CalendarDay struct {
+	Date     time.Time      `json:"date"`
+	Kind     DayKind        `json:"kind"`
+	Name     string         `json:"name,omitempty"`
+	Expected *time.Duration `json:"expected,omitempty"`
+}
+
+// Calendar is the collection of days that override the schedule for all customers
+type Calendar []*CalendarDay
+
+// ParseDayKind returns the DayKind matching the given name
+func ParseDayKind(name string) (DayKind, error) {
+	for kind, kindName := range dayKindNames {
+		if strings.EqualFold(name, kindName) {
+			return kind, nil
+		}
+	}
+	return DayHoliday, fmt.Errorf("%w: %q", ErrInvalidDayKind, name)
+}
+
+// DayKindOptions returns the valid names for DayKind, for use in help texts
+func DayKindOptions() string {
+	return strings.Join(
+		[]string{
+			DayHoliday.String(),
+			DayVacation.String(),
+			DaySickLeave.String(),
+			DayAbsence.String(),
+		},
+		", ",
+	)
+}
+
+func (kind DayKind) String() string {
+	if name, ok := dayKindNames[kind]; ok {
+		return name
+	}
+	return fmt.Sprintf("DayKind(%d)", kind)
+}
+
+// MarshalText makes DayKind serialize by name
+func (kind DayKind) MarshalText() ([]byte, error) {
+	if _, ok := dayKindNames[kind]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidDayKind, kind)
+	}
+	return []byte(kind.String()), nil
+}
+
+// UnmarshalText parses a DayKind by name
+func (kind *DayKind) UnmarshalText(text []byte) error {
+	parsed, err := ParseDayKind(string(text))
+	if err != nil {
+		return err
+	}
+	*kind = parsed
+	return nil
+}
+
+// ExpectedWork returns how much work is expected on the day
+func (day CalendarDay) ExpectedWork() time.Duration {
+	if day.Expected == nil {
+		return 0
+	}
+	return *day.Expected
+}
+
+// String returns a short description of the day, like "holiday: Christmas Day"
+func (day CalendarDay) String() string {
+	return day.Format(time.Duration.String)
+}
+
+// Format returns the same description as String, with the expected work formatted by the given function
+func (day CalendarDay) Format(formatDuration func(time.Duration) string) string {
+	desc := day.Kind.String()
+	if day.Name != "" {
+		desc = fmt.Sprintf("%s: %s", desc, day.Name)
+	}
+	if day.Expected != nil {
+		desc = fmt.Sprintf("%s (%s expected)", desc, formatDuration(*day.Expected))
+	}
+	return desc
+}
+
+// Len returns how many days are in the Calendar
+func (calendar Calendar) Len() int {
+	return len(calendar)
+}
+
+// IndexOf returns the index of the day matching the given date, or -1 if not found
+func (calendar Calendar) IndexOf(date time.Time) int {
+	for idx := range calendar {
+		if SameDate(calendar[idx].Date, date) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Get returns the day matching the given date, or nil if not found
+func (calendar Calendar) Get(date time.Time) *CalendarDay {
+	idx := calendar.IndexOf(date)
+	if idx == -1 {
+		return nil
+	}
+	return calendar[idx]
+}
+
+// Set will add the given day to the Calendar if no day with the same date exists.
+// If overwrite is true, it will replace the day if already present.
+// Returns true if the day is set, false if not.
+func (calendar *Calendar) Set(day CalendarDay, overwrite bool) bool {
+	day.Date = DateOf(day.Date)
+	idx := calendar.IndexOf(day.Date)
+	if idx == -1 {
+		*calendar = append(*calendar, &day)
+		return true
+	}
+	if overwrite {
+		(*calendar)[idx] = &day
+		return true
+	}
+	return false
+}
+
+// Delete removes the day matching the given date.
+// Returns true if found and removed, false if not.
+func (calendar *Calendar) Delete(date time.Time) bool {
+	idx := calendar.IndexOf(date)
+	if idx == -1 {
+		return false
+	}
+	copy((*calendar)[idx:], (*calendar)[idx+1:])
+	(*calendar)[len(*calendar)-1] = nil
+	*calendar = (*calendar)[:len(*calendar)-1]
+
+	return true
+}
+
+// FilterByDateRange returns a new Calendar with the days within the given range, inclusive.
+func (calendar Calendar) FilterByDateRange(from, to time.Time) Calendar {
+	filtered := make(Calendar, 0)
+	for _, day := range calendar {
+		if !day.Date.Before(from) && !day.Date.After(to) {
+			filtered = append(filtered, day)
+		}
+	}
+	return filtered
+}
+
+// Sort sorts the Calendar by date, ascending
+func (calendar Calendar) Sort() {
+	sort.Slice(calendar, func(i, j int) bool {
+		return calendar[i].Date.Before(calendar[j].Date)
+	})
+}
@@ -0,0 +1,99 @@
+package flex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDayKind(t *testing.T) {
+	kind, err := ParseDayKind("Vacation")
+	assert.NoError(t, err)
+	assert.Equal(t, DayVacation, kind)
+
+	_, err = ParseDayKind("party")
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrInvalidDayKind)
+	}
+}
+
+func TestDayKindJSON(t *testing.T) {
+	day := CalendarDay{
+		Date: time.Date(2021, time.December, 24, 0, 0, 0, 0, time.UTC),
+		Kind: DaySickLeave,
+	}
+	data, err := json.Marshal(day)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"date":"2021-12-24T00:00:00Z","kind":"sick"}`, string(data))
+
+	decoded := CalendarDay{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, day, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"kind":"party"}`), &decoded))
+}
+
+func TestCalendarDayString(t *testing.T) {
+	halfDay := 4 * time.Hour
+	assert.Equal(t, "holiday", CalendarDay{}.String())
+	assert.Equal(
+		t,
+		"vacation: Summer (4h0m0s expected)",
+		CalendarDay{Kind: DayVacation, Name: "Summer", Expected: &halfDay}.String(),
+	)
+	assert.Equal(
+		t,
+		"vacation: Summer (+4:00 expected)",
+		CalendarDay{Kind: DayVacation, Name: "Summer", Expected: &halfDay}.Format(func(d time.Duration) string {
+			return DurationFormatClock.Format(d, 0)
+		}),
+	)
+}
+
+func TestCalendarSetGetDelete(t *testing.T) {
+	christmas := time.Date(2021, time.December, 24, 13, 0, 0, 0, time.UTC)
+	calendar := make(Calendar, 0)
+
+	assert.True(t, calendar.Set(CalendarDay{Date: christmas, Name: "Christmas"}, false))
+	assert.False(t, calendar.Set(CalendarDay{Date: christmas, Name: "Xmas"}, false))
+	assert.True(t, calendar.Set(CalendarDay{Date: christmas, Name: "Xmas"}, true))
+	assert.Equal(t, 1, calendar.Len())
+
+	day := calendar.Get(DateOf(christmas))
+	if assert.NotNil(t, day) {
+		assert.Equal(t, "Xmas", day.Name)
+		assert.True(t, DateOf(christmas).Equal(day.Date))
+	}
+	assert.Nil(t, calendar.Get(christmas.Add(24*time.Hour)))
+
+	assert.False(t, calendar.Delete(christmas.Add(24*time.Hour)))
+	assert.True(t, calendar.Delete(christmas))
+	assert.Equal(t, 0, calendar.Len())
+}
+
+func TestDBExpectedWorkWithCalendar(t *testing.T) {
+	halfDay := 4 * time.Hour
+	monday := time.Date(2021, time.December, 6, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	wednesday := monday.AddDate(0, 0, 2)
+	db := &DB{
+		Customers: Customers{{Name: "Customer1"}},
+		Calendar: Calendar{
+			{Date: monday, Kind: DayHoliday},
+			{Date: tuesday, Kind: DayVacation, Expected: &halfDay},
+		},
+	}
+	customer := db.Customers[0]
+
+	assert.Equal(t, time.Duration(0), db.ExpectedWork(customer, monday))
+	assert.Equal(t, halfDay, db.ExpectedWork(customer, tuesday))
+	assert.Equal(t, DefaultWorkdayLength, db.ExpectedWork(customer, wednesday))
+
+	entry, err := db.SetWorkedForCustomer("customer1", Entry{Date: monday, Worked: 2 * time.Hour}, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, 2*time.Hour, entry.Amount)
+	}
+}
@@ -0,0 +1,151 @@
+package flex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFileEnv is the environment variable that may point to another config file than the default
+const ConfigFileEnv = "FLEXTIME_CONFIG"
+
+// Config holds per-user defaults, loaded from a TOML file. Empty fields are not set.
+// Example:
+//
+//	file = "~/.local/share/flextime/flextime.json"
+//	customer = "acme"
+//	workday = "7h30m"
+//	output = "text"
+//	customer_sort = "asc"
+//	entry_sort = "datedesc"
+//	duration_format = "clock"
+//	backups = 5
+//	locale = "sv_SE"
+type Config struct {
+	FileName       string `toml:"-"`
+	File           string `toml:"file"`
+	Customer       string `toml:"customer"`
+	Workday        string `toml:"workday"`
+	Output         string `toml:"output"`
+	CustomerSort   string `toml:"customer_sort"`
+	EntrySort      string `toml:"entry_sort"`
+	DurationFormat string `toml:"duration_format"`
+	Backups        *int   `toml:"backups"`
+	Locale         string `toml:"locale"`
+}
+
+// DefaultConfigFileName returns the path of the config file to use if not told otherwise,
+// which is flextime/config.toml in the users config dir, e.g. $XDG_CONFIG_HOME on Linux
+func DefaultConfigFileName() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "flextime", "config.toml"), nil
+}
+
+// ConfigFileName returns the config file named by ConfigFileEnv if set, otherwise DefaultConfigFileName
+func ConfigFileName() (string, error) {
+	if fileName, present := os.LookupEnv(ConfigFileEnv); present {
+		return fileName, nil
+	}
+	return DefaultConfigFileName()
+}
+
+// LoadConfig reads the config from the given TOML file. A file that doesn't exist gives an empty Config,
+// so that having no config file is the same as having one with nothing in it.
+// Unknown keys are an error, as they are most likely misspelled.
+func LoadConfig(fileName string) (*Config, error) {
+	config := &Config{FileName: fileName}
+	if fileName == "" {
+		return config, nil
+	}
+	meta, err := toml.DecodeFile(fileName, config)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, fileName, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("%w: %s: unknown keys: %s", ErrInvalidConfig, fileName, strings.Join(keys, ", "))
+	}
+	config.File = expandHome(config.File)
+	return config, config.Validate()
+}
+
+// Validate checks the values that can be checked without knowing where they are used
+func (config *Config) Validate() error {
+	if _, err := config.WorkdayLength(); err != nil {
+		return err
+	}
+	if config.DurationFormat != "" {
+		if _, err := ParseDurationFormat(config.DurationFormat); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, config.FileName, err)
+		}
+	}
+	if config.Backups != nil && *config.Backups < 0 {
+		return fmt.Errorf("%w: %s: negative backups", ErrInvalidConfig, config.FileName)
+	}
+	return nil
+}
+
+// WorkdayLength returns the configured workday, or 0 if not set.
+// The workday must have a unit, like "8h" or "7:30", as a bare number would be minutes.
+func (config *Config) WorkdayLength() (time.Duration, error) {
+	if config.Workday == "" {
+		return 0, nil
+	}
+	if integerDurationExpr.MatchString(strings.TrimSpace(config.Workday)) {
+		return 0, fmt.Errorf("%w: %s: workday %q needs a unit, like 8h", ErrInvalidConfig, config.FileName, config.Workday)
+	}
+	workday, err := ParseFlexDuration(config.Workday)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: workday: %v", ErrInvalidConfig, config.FileName, err)
+	}
+	if workday <= 0 || workday > 24*time.Hour {
+		return 0, fmt.Errorf("%w: %s: workday %q is not within a day", ErrInvalidConfig, config.FileName, config.Workday)
+	}
+	return workday, nil
+}
+
+// commaLanguages are the languages that write decimals with a comma
+var commaLanguages = map[string]bool{
+	"bg": true, "ca": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "et": true,
+	"fi": true, "fr": true, "hr": true, "hu": true, "id": true, "is": true, "it": true, "lt": true,
+	"lv": true, "nb": true, "nl": true, "nn": true, "no": true, "pl": true, "pt": true, "ro": true,
+	"ru": true, "sk": true, "sl": true, "sr": true, "sv": true, "tr": true, "uk": true, "vi": true,
+}
+
+// DecimalComma returns true if the configured locale, like "sv_SE" or "de_DE.UTF-8",
+// writes decimals with a comma rather than a point
+func (config *Config) DecimalComma() bool {
+	language, _, _ := strings.Cut(strings.ToLower(config.Locale), "_")
+	language, _, _ = strings.Cut(language, "-")
+	language, _, _ = strings.Cut(language, ".")
+	return commaLanguages[language]
+}
+
+// expandHome replaces a leading ~ in the path with the users home dir
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,93 @@
+package flex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(fileName, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestLoadConfig(t *testing.T) {
+	fileName := writeConfig(t, `
+file = "/tmp/flex.json"
+customer = "acme"
+workday = "7:30"
+output = "json"
+customer_sort = "asc"
+entry_sort = "datedesc"
+duration_format = "clock"
+backups = 0
+locale = "sv_SE.UTF-8"
+`)
+	config, err := LoadConfig(fileName)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/tmp/flex.json", config.File)
+		assert.Equal(t, "acme", config.Customer)
+		assert.Equal(t, "json", config.Output)
+		assert.Equal(t, "asc", config.CustomerSort)
+		assert.Equal(t, "datedesc", config.EntrySort)
+		assert.Equal(t, "clock", config.DurationFormat)
+		if assert.NotNil(t, config.Backups) {
+			assert.Equal(t, 0, *config.Backups)
+		}
+		workday, err := config.WorkdayLength()
+		assert.NoError(t, err)
+		assert.Equal(t, 7*time.Hour+30*time.Minute, workday)
+		assert.True(t, config.DecimalComma())
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "nonexistent.toml"))
+	if assert.NoError(t, err) {
+		assert.Nil(t, config.Backups)
+		assert.False(t, config.DecimalComma())
+		workday, err := config.WorkdayLength()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), workday)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		content string
+		reason  string
+	}{
+		{`custmer = "acme"`, "unknown keys: custmer"},
+		{`workday = "8"`, "needs a unit"},
+		{`workday = "25h"`, "not within a day"},
+		{`duration_format = "fortnights"`, "invalid duration format"},
+		{`backups = -1`, "negative backups"},
+		{`backups = "three"`, "backups"},
+	}
+	for _, tt := range tests {
+		_, err := LoadConfig(writeConfig(t, tt.content))
+		if assert.ErrorIs(t, err, ErrInvalidConfig, tt.content) {
+			assert.Contains(t, err.Error(), tt.reason, tt.content)
+		}
+	}
+}
+
+func TestConfigDecimalComma(t *testing.T) {
+	for locale, expected := range map[string]bool{
+		"":            false,
+		"en_US":       false,
+		"C":           false,
+		"de_DE.UTF-8": true,
+		"nb-NO":       true,
+		"fr":          true,
+	} {
+		assert.Equal(t, expected, (&Config{Locale: locale}).DecimalComma(), locale)
+	}
+}
@@ -1,8 +1,14 @@
 package flex
 
+import "time"
+
 const (
-	ShortDateFormat     = "2006-01-02"
-	DefaultCustomerName = "default"
+	ShortDateFormat      = "2006-01-02"
+	ClockFormat          = "15:04"
+	DefaultCustomerName  = "default"
+	DefaultWorkdayLength = 8 * time.Hour
+	DefaultBackupCount   = 3
+	DefaultLockTimeout   = 5 * time.Second
 )
 
 type EntrySortOrder uint8
@@ -0,0 +1,284 @@
+package flex
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AmountFormat is how amounts of time are written in imported data
+type AmountFormat uint8
+
+const (
+	AmountFlex    AmountFormat = iota // anything accepted by ParseFlexDuration
+	AmountHours                       // a number of hours, e.g. 1.5 or -0,75
+	AmountMinutes                     // a number of minutes, e.g. 90
+)
+
+var amountFormatNames = map[AmountFormat]string{
+	AmountFlex:    "flex",
+	AmountHours:   "hours",
+	AmountMinutes: "minutes",
+}
+
+// ParseAmountFormat returns the AmountFormat matching the given name
+func ParseAmountFormat(name string) (AmountFormat, error) {
+	for format, formatName := range amountFormatNames {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return AmountFlex, fmt.Errorf("%w: %q", ErrInvalidAmountFormat, name)
+}
+
+// AmountFormatOptions returns the valid names for AmountFormat, for use in help texts
+func AmountFormatOptions() string {
+	return strings.Join(
+		[]string{
+			AmountFlex.String(),
+			AmountHours.String(),
+			AmountMinutes.String(),
+		},
+		", ",
+	)
+}
+
+func (format AmountFormat) String() string {
+	if name, ok := amountFormatNames[format]; ok {
+		return name
+	}
+	return fmt.Sprintf("AmountFormat(%d)", format)
+}
+
+// Parse returns the amount of time in the text, written according to the format.
+// Hours and minutes may have decimals, with either a decimal point or comma.
+func (format AmountFormat) Parse(text string) (time.Duration, error) {
+	var unit time.Duration
+	switch format {
+	case AmountHours:
+		unit = time.Hour
+	case AmountMinutes:
+		unit = time.Minute
+	default:
+		return ParseFlexDuration(text)
+	}
+	number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a number of %s", ErrInvalidDuration, text, format)
+	}
+	return time.Duration(math.Round(number * float64(unit))), nil
+}
+
+// CSVMapping names the CSV columns, by their header, that entry fields are read from.
+// Date is required, as is one of Amount and Worked. Columns not mentioned are ignored.
+type CSVMapping struct {
+	Date     string
+	Amount   string
+	Worked   string
+	Customer string
+	Comment  string
+}
+
+// DefaultCSVMapping reads each field from a column with the same name
+var DefaultCSVMapping = CSVMapping{
+	Date:     "date",
+	Amount:   "amount",
+	Worked:   "worked",
+	Customer: "customer",
+	Comment:  "comment",
+}
+
+// ParseCSVMapping parses a comma separated list of field=column pairs, like "date=Date,amount=Hours".
+// Fields not given are read from a column with the same name as the field, if there is one.
+func ParseCSVMapping(spec string) (CSVMapping, error) {
+	mapping := DefaultCSVMapping
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, column, found := strings.Cut(part, "=")
+		column = strings.TrimSpace(column)
+		if !found || column == "" {
+			return mapping, fmt.Errorf("%w: expected field=column, got %q", ErrInvalidCSVMapping, part)
+		}
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "date":
+			mapping.Date = column
+		case "amount":
+			mapping.Amount = column
+		case "worked":
+			mapping.Worked = column
+		case "customer":
+			mapping.Customer = column
+		case "comment":
+			mapping.Comment = column
+		default:
+			return mapping, fmt.Errorf(
+				"%w: unknown field %q (options: date, amount, worked, customer, comment)",
+				ErrInvalidCSVMapping,
+				field,
+			)
+		}
+	}
+	return mapping, nil
+}
+
+// CSVImportOptions tells ReadCSVEntries how to read the file
+type CSVImportOptions struct {
+	Mapping      CSVMapping
+	Delimiter    rune         // 0 means comma
+	DateLayout   string       // Go time layout, empty means ShortDateFormat
+	AmountFormat AmountFormat // for both amount and worked
+}
+
+// csvColumns holds the index of each mapped column in a row, or -1 if not in the file
+type csvColumns struct {
+	date, amount, worked, customer, comment int
+}
+
+func findCSVColumns(header []string, mapping CSVMapping) (csvColumns, error) {
+	find := func(name string) int {
+		for idx, column := range header {
+			if name != "" && strings.EqualFold(strings.TrimSpace(column), name) {
+				return idx
+			}
+		}
+		return -1
+	}
+	columns := csvColumns{
+		date:     find(mapping.Date),
+		amount:   find(mapping.Amount),
+		worked:   find(mapping.Worked),
+		customer: find(mapping.Customer),
+		comment:  find(mapping.Comment),
+	}
+	if columns.date == -1 {
+		return columns, fmt.Errorf("%w: no date column %q in header", ErrInvalidCSVMapping, mapping.Date)
+	}
+	if columns.amount == -1 && columns.worked == -1 {
+		return columns, fmt.Errorf(
+			"%w: no amount column %q or worked column %q in header",
+			ErrInvalidCSVMapping,
+			mapping.Amount,
+			mapping.Worked,
+		)
+	}
+	return columns, nil
+}
+
+// ReadCSVEntries reads entries from CSV with a header line, according to the options.
+// Lines that can't be read as an entry are returned as rejected, along with the ones that could,
+// so that all problems can be reported at once. The error is only for problems with the file as a whole.
+// Worked time is returned as is, as the flex amount depends on the schedule of the customer it's imported for.
+func ReadCSVEntries(reader io.Reader, options CSVImportOptions) ([]ImportRow, []RejectedRow, error) {
+	csvReader := csv.NewReader(reader)
+	if options.Delimiter != 0 {
+		csvReader.Comma = options.Delimiter
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	dateLayout := options.DateLayout
+	if dateLayout == "" {
+		dateLayout = ShortDateFormat
+	}
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("%w: no header", ErrInvalidCSVMapping)
+		}
+		return nil, nil, err
+	}
+	columns, err := findCSVColumns(header, options.Mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]ImportRow, 0)
+	rejected := make([]RejectedRow, 0)
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rejected = append(rejected, RejectedRow{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return nil, nil, err
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		row, err := parseCSVRecord(record, columns, dateLayout, options.AmountFormat)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: err})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	return rows, rejected, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCSVRecord(record []string, columns csvColumns, dateLayout string, amountFormat AmountFormat) (ImportRow, error) {
+	field := func(idx int) string {
+		if idx < 0 || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	row := ImportRow{Customer: field(columns.customer)}
+	dateText := field(columns.date)
+	if dateText == "" {
+		return row, fmt.Errorf("%w: empty date", ErrInvalidDate)
+	}
+	date, err := time.Parse(dateLayout, dateText)
+	if err != nil {
+		return row, fmt.Errorf("%w: %q does not match %s", ErrInvalidDate, dateText, dateLayout)
+	}
+
+	amountText, workedText := field(columns.amount), field(columns.worked)
+	switch {
+	case amountText != "" && workedText != "":
+		return row, fmt.Errorf("%w: both amount and worked", ErrInvalidDuration)
+	case amountText != "":
+		row.Entry.Amount, err = amountFormat.Parse(amountText)
+		if err == nil && row.Entry.Amount == 0 {
+			err = fmt.Errorf("%w: amount must not be zero", ErrInvalidDuration)
+		}
+	case workedText != "":
+		row.Entry.Worked, err = amountFormat.Parse(workedText)
+		if err == nil && row.Entry.Worked <= 0 {
+			err = fmt.Errorf("%w: worked time must be positive", ErrInvalidDuration)
+		}
+	default:
+		err = fmt.Errorf("%w: no amount or worked time", ErrInvalidDuration)
+	}
+	if err != nil {
+		return row, err
+	}
+
+	row.Entry.Date = DateOf(date)
+	row.Entry.Comment = field(columns.comment)
+	return row, nil
+}
@@ -0,0 +1,106 @@
+package flex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountFormatParse(t *testing.T) {
+	tests := []struct {
+		format AmountFormat
+		input  string
+		want   time.Duration
+		err    error
+	}{
+		{AmountFlex, "1:30", 90 * time.Minute, nil},
+		{AmountFlex, "90", 90 * time.Minute, nil},
+		{AmountHours, "8", 8 * time.Hour, nil},
+		{AmountHours, "-0,75", -45 * time.Minute, nil},
+		{AmountHours, "1:30", 0, ErrInvalidDuration},
+		{AmountMinutes, "90", 90 * time.Minute, nil},
+		{AmountMinutes, "7.5", 7*time.Minute + 30*time.Second, nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.format.Parse(tt.input)
+		assert.ErrorIs(t, err, tt.err, "%s %q", tt.format, tt.input)
+		assert.Equal(t, tt.want, got, "%s %q", tt.format, tt.input)
+	}
+
+	format, err := ParseAmountFormat("Hours")
+	assert.NoError(t, err)
+	assert.Equal(t, AmountHours, format)
+	_, err = ParseAmountFormat("days")
+	assert.ErrorIs(t, err, ErrInvalidAmountFormat)
+}
+
+func TestParseCSVMapping(t *testing.T) {
+	mapping, err := ParseCSVMapping("date=Date, amount=Hours,customer=Client")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		CSVMapping{Date: "Date", Amount: "Hours", Worked: "worked", Customer: "Client", Comment: "comment"},
+		mapping,
+	)
+
+	mapping, err = ParseCSVMapping("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultCSVMapping, mapping)
+
+	for _, spec := range []string{"date", "date=", "hours=Hours"} {
+		_, err = ParseCSVMapping(spec)
+		assert.ErrorIs(t, err, ErrInvalidCSVMapping, spec)
+	}
+}
+
+func TestReadCSVEntries(t *testing.T) {
+	input := strings.Join(
+		[]string{
+			"Date;Hours;Client;Note",
+			"03.01.2022;1,5;acme;late night",
+			"04.01.2022;-2;;",
+			"",
+			"2022-01-05;1;acme;",
+			"06.01.2022;;acme;",
+			"07.01.2022;lots;acme;",
+			"08.01.2022;0;acme;",
+		},
+		"\n",
+	)
+	mapping, err := ParseCSVMapping("date=Date,amount=Hours,customer=Client,comment=Note")
+	assert.NoError(t, err)
+
+	rows, rejected, err := ReadCSVEntries(
+		strings.NewReader(input),
+		CSVImportOptions{
+			Mapping:      mapping,
+			Delimiter:    ';',
+			DateLayout:   "02.01.2006",
+			AmountFormat: AmountHours,
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]ImportRow{
+			{Line: 2, Customer: "acme", Entry: Entry{Date: ymd(2022, 1, 3), Amount: 90 * time.Minute, Comment: "late night"}},
+			{Line: 3, Entry: Entry{Date: ymd(2022, 1, 4), Amount: -2 * time.Hour}},
+		},
+		rows,
+	)
+	if assert.Len(t, rejected, 4) {
+		assert.Equal(t, 5, rejected[0].Line)
+		assert.ErrorIs(t, rejected[0].Err, ErrInvalidDate)
+		assert.Equal(t, 6, rejected[1].Line)
+		assert.ErrorIs(t, rejected[1].Err, ErrInvalidDuration)
+		assert.Equal(t, 7, rejected[2].Line)
+		assert.ErrorIs(t, rejected[2].Err, ErrInvalidDuration)
+		assert.Equal(t, 8, rejected[3].Line)
+		assert.ErrorIs(t, rejected[3].Err, ErrInvalidDuration)
+	}
+
+	_, _, err = ReadCSVEntries(strings.NewReader("day,hours\n"), CSVImportOptions{Mapping: DefaultCSVMapping})
+	assert.ErrorIs(t, err, ErrInvalidCSVMapping)
+}
@@ -1,6 +1,8 @@
 package flex
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,13 +10,28 @@ import (
 )
 
 type Customer struct {
-	Name    string  `json:"customer_name,omitempty"`
-	Entries Entries `json:"flex_entries,omitempty"`
+	Name     string    `json:"customer_name,omitempty"`
+	Entries  Entries   `json:"flex_entries,omitempty"`
+	Session  *Session  `json:"session,omitempty"`
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Metadata, for people rather than for calculations
+	DisplayName string     `json:"display_name,omitempty"` // e.g. the full company name, where Name is short to type
+	Notes       string     `json:"notes,omitempty"`
+	Archived    bool       `json:"archived,omitempty"` // no longer active
+	StartDate   *time.Time `json:"start_date,omitempty"`
 }
 
 type Customers []*Customer
 type CustomersByName Customers
 
+// Title returns the DisplayName if set, otherwise the Name
+func (customer Customer) Title() string {
+	if customer.DisplayName != "" {
+		return customer.DisplayName
+	}
+	return customer.Name
+}
+
 // GetTotalFlex returns the sum of Amount for all Entries
 func (customer Customer) GetTotalFlex() time.Duration {
 	if customer.Entries == nil || customer.Entries.Len() == 0 {
@@ -23,7 +40,7 @@ func (customer Customer) GetTotalFlex() time.Duration {
 	return customer.Entries.GetTotalFlex()
 }
 
-// GetEntry returns the entry matching the given date, or nil + error if not found
+// GetEntry returns the first entry matching the given date, or nil + error if not found
 func (customer *Customer) GetEntry(date time.Time) (*Entry, error) {
 	if customer.Entries == nil || customer.Entries.Len() == 0 {
 		return nil, ErrNoEntries
@@ -35,24 +52,111 @@ func (customer *Customer) GetEntry(date time.Time) (*Entry, error) {
 	return customer.Entries[idx], nil
 }
 
+// GetEntries returns all entries matching the given date, or nil + error if none found
+func (customer *Customer) GetEntries(date time.Time) (Entries, error) {
+	if customer.Entries == nil || customer.Entries.Len() == 0 {
+		return nil, ErrNoEntries
+	}
+	entries := customer.Entries.FilterByDate(date)
+	if entries.Len() == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNoEntry, date)
+	}
+	return entries, nil
+}
+
+// GetEntryByID returns the entry with the given ID, or nil + error if not found
+func (customer *Customer) GetEntryByID(id string) (*Entry, error) {
+	idx := customer.Entries.IndexOfID(id)
+	if idx == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchEntryID, id)
+	}
+	return customer.Entries[idx], nil
+}
+
 // SetEntry will, if overwrite is false, add the given Entry to the customers Entries,
 // if it does not already exist.
 // If overwrite is true, it will replace the entry if already present.
+// If the given Entry has an ID, only the entry with that ID is considered a match,
+// otherwise any entry for the same date is, and overwriting replaces all entries for that date.
 // Returns true if an Entry is set, false if not.
 func (customer *Customer) SetEntry(entry Entry, overwrite bool) bool {
+	return customer.setEntry(entry, overwrite) != nil
+}
+
+// setEntry works like SetEntry, but returns a pointer to the stored Entry, or nil if not set
+func (customer *Customer) setEntry(entry Entry, overwrite bool) *Entry {
 	foundAtIndex := -1
 	if customer.Entries != nil && customer.Entries.Len() > 0 {
 		foundAtIndex = customer.Entries.IndexOf(entry)
 	}
 	if foundAtIndex == -1 {
-		customer.Entries = append(customer.Entries, &entry)
-		return true
+		return customer.AddEntry(entry)
 	}
-	if overwrite {
+	if !overwrite {
+		return nil
+	}
+	if entry.ID != "" {
 		customer.Entries[foundAtIndex] = &entry
-		return true
+		return &entry
+	}
+	customer.Entries.DeleteByDate(entry.Date)
+	return customer.AddEntry(entry)
+}
+
+// AddEntry adds the given Entry to the customers Entries, regardless of other entries for the same date.
+// The Entry is given a new ID, unless it already has one not in use by another entry.
+// Returns a pointer to the added Entry.
+func (customer *Customer) AddEntry(entry Entry) *Entry {
+	if entry.ID == "" || customer.Entries.IndexOfID(entry.ID) != -1 {
+		entry.ID = customer.newEntryID()
+	}
+	customer.Entries = append(customer.Entries, &entry)
+	return &entry
+}
+
+// AssignEntryIDs gives every entry that is missing an ID one derived from the customer name
+// and the position and content of the entry, so that reading the same file again gives the same IDs,
+// even before they are saved. Returns how many IDs were assigned.
+func (customer *Customer) AssignEntryIDs() int {
+	assigned := 0
+	for idx, entry := range customer.Entries {
+		if entry.ID == "" {
+			entry.ID = customer.derivedEntryID(idx, *entry)
+			assigned++
+		}
+	}
+	return assigned
+}
+
+// derivedEntryID returns an ID made from a hash of the customer name and the position and content
+// of the entry, not in use by any of the customers entries
+func (customer *Customer) derivedEntryID(idx int, entry Entry) string {
+	for attempt := 0; ; attempt++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf(
+			"%s\x00%d\x00%d\x00%s\x00%d\x00%d\x00%s",
+			customer.Name,
+			idx,
+			attempt,
+			entry.Date.Format(time.RFC3339Nano),
+			entry.Amount,
+			entry.Worked,
+			entry.Comment,
+		)))
+		id := hex.EncodeToString(sum[:4])
+		if customer.Entries.IndexOfID(id) == -1 {
+			return id
+		}
+	}
+}
+
+// newEntryID returns an ID not in use by any of the customers entries
+func (customer *Customer) newEntryID() string {
+	for {
+		id := NewEntryID()
+		if customer.Entries.IndexOfID(id) == -1 {
+			return id
+		}
 	}
-	return false
 }
 
 func (customers Customers) Len() int {
@@ -137,6 +137,77 @@ func TestCustomerSetEntry(t *testing.T) {
 	)
 }
 
+func TestCustomerAddEntry(t *testing.T) {
+	today := time.Now()
+	customer := &Customer{Name: "MyCompany"}
+
+	first := customer.AddEntry(Entry{Date: today, Amount: time.Hour, Comment: "morning"})
+	second := customer.AddEntry(Entry{Date: today, Amount: 30 * time.Minute, Comment: "evening"})
+	assert.NotEmpty(t, first.ID)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, 90*time.Minute, customer.GetTotalFlex())
+
+	// an ID already in use is replaced
+	third := customer.AddEntry(Entry{ID: first.ID, Date: today, Amount: time.Minute})
+	assert.NotEqual(t, first.ID, third.ID)
+
+	entries, err := customer.GetEntries(today)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, entries.Len())
+
+	entry, err := customer.GetEntryByID(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "evening", entry.Comment)
+
+	_, err = customer.GetEntryByID("nope")
+	assert.ErrorIs(t, err, ErrNoSuchEntryID)
+}
+
+func TestCustomerSetEntryWithSeveralEntries(t *testing.T) {
+	today := time.Now()
+	customer := &Customer{Name: "MyCompany"}
+	first := customer.AddEntry(Entry{Date: today, Amount: time.Hour})
+	customer.AddEntry(Entry{Date: today, Amount: 30 * time.Minute})
+
+	// matching on ID only replaces that entry
+	assert.False(t, customer.SetEntry(Entry{ID: first.ID, Date: today, Amount: 2 * time.Hour}, false))
+	assert.True(t, customer.SetEntry(Entry{ID: first.ID, Date: today, Amount: 2 * time.Hour}, true))
+	assert.Equal(t, 2, customer.Entries.Len())
+	assert.Equal(t, 150*time.Minute, customer.GetTotalFlex())
+
+	// matching on date replaces the whole day
+	assert.True(t, customer.SetEntry(Entry{Date: today, Amount: 15 * time.Minute}, true))
+	assert.Equal(t, 1, customer.Entries.Len())
+	assert.Equal(t, 15*time.Minute, customer.GetTotalFlex())
+	assert.NotEmpty(t, customer.Entries[0].ID)
+}
+
+func TestCustomerAssignEntryIDs(t *testing.T) {
+	today := time.Now()
+	newCustomer := func() *Customer {
+		return &Customer{
+			Name: "MyCompany",
+			Entries: Entries{
+				{Date: today, Amount: time.Hour},
+				{ID: "a", Date: today},
+				{Date: today, Amount: time.Hour},
+			},
+		}
+	}
+	customer := newCustomer()
+	assert.Equal(t, 2, customer.AssignEntryIDs())
+	assert.NotEmpty(t, customer.Entries[0].ID)
+	assert.Equal(t, "a", customer.Entries[1].ID)
+	assert.NotEqual(t, customer.Entries[0].ID, customer.Entries[2].ID)
+	assert.Equal(t, 0, customer.AssignEntryIDs())
+
+	// The same entries are given the same IDs again, as when the same file is read twice
+	again := newCustomer()
+	again.AssignEntryIDs()
+	assert.Equal(t, customer.Entries[0].ID, again.Entries[0].ID)
+	assert.Equal(t, customer.Entries[2].ID, again.Entries[2].ID)
+}
+
 func TestCustomersSortAscending(t *testing.T) {
 	c1 := &Customer{Name: "CustomerA"}
 	c2 := &Customer{Name: "CustomerB"}
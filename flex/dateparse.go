@@ -0,0 +1,174 @@
+package flex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A DateRange is a range of dates, From and To inclusive
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+var (
+	relativeDateExpr = regexp.MustCompile(`^([+-])(\d+)([dwmy])$`)
+	isoWeekExpr      = regexp.MustCompile(`^(\d{4})-[wW](\d{1,2})$`)
+	monthExpr        = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	quarterExpr      = regexp.MustCompile(`^(\d{4})-[qQ]([1-4])$`)
+	yearExpr         = regexp.MustCompile(`^\d{4}$`)
+)
+
+// DateInputHelp describes the input accepted by ParseDateRange, for use in help texts
+const DateInputHelp = "YYYY-MM-DD, today, yesterday, tomorrow, -3d, +1w, -2m, -1y, [last|next] monday, YYYY-Www, YYYY-MM, YYYY-Qn, YYYY"
+
+// PeriodInputHelp describes the named ranges accepted by ParsePeriodRange, for use in help texts
+const PeriodInputHelp = "this-week, last-month, next-year, etc., or anything accepted as a date"
+
+func singleDate(date time.Time) DateRange {
+	return DateRange{From: date, To: date}
+}
+
+func periodRange(period Period, date time.Time) DateRange {
+	return DateRange{From: period.Start(date), To: period.Next(date).AddDate(0, 0, -1)}
+}
+
+// isoWeekStart returns the Monday of the given ISO 8601 week
+func isoWeekStart(year, week int) time.Time {
+	// January 4th is always in week 1
+	return PeriodWeek.Start(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, (week-1)*7)
+}
+
+// ParseDateRange parses a date given in one of the forms in DateInputHelp, relative to the date of now.
+// Input naming a single date gives a range of just that date, while input naming a longer period,
+// like "2022-W14" or "2022-03", gives the whole period.
+func ParseDateRange(input string, now time.Time) (DateRange, error) {
+	today := DateOf(now)
+	text := strings.ToLower(strings.Join(strings.Fields(input), " "))
+
+	switch text {
+	case "":
+		return DateRange{}, fmt.Errorf("%w: empty date", ErrInvalidDate)
+	case "today", "now":
+		return singleDate(today), nil
+	case "yesterday":
+		return singleDate(today.AddDate(0, 0, -1)), nil
+	case "tomorrow":
+		return singleDate(today.AddDate(0, 0, 1)), nil
+	}
+
+	if date, err := time.Parse(ShortDateFormat, text); err == nil {
+		return singleDate(date), nil
+	}
+
+	if match := relativeDateExpr.FindStringSubmatch(text); match != nil {
+		count, err := strconv.Atoi(match[2])
+		if err != nil {
+			return DateRange{}, fmt.Errorf("%w: %q: %v", ErrInvalidDate, input, err)
+		}
+		if match[1] == "-" {
+			count = -count
+		}
+		switch match[3] {
+		case "d":
+			return singleDate(today.AddDate(0, 0, count)), nil
+		case "w":
+			return singleDate(today.AddDate(0, 0, count*7)), nil
+		case "m":
+			return singleDate(today.AddDate(0, count, 0)), nil
+		default:
+			return singleDate(today.AddDate(count, 0, 0)), nil
+		}
+	}
+
+	if match := isoWeekExpr.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+		start := isoWeekStart(year, week)
+		if _, lastWeek := isoWeekStart(year+1, 1).AddDate(0, 0, -1).ISOWeek(); week < 1 || week > lastWeek {
+			return DateRange{}, fmt.Errorf("%w: %q: %d has no week %d", ErrInvalidDate, input, year, week)
+		}
+		return periodRange(PeriodWeek, start), nil
+	}
+
+	if match := monthExpr.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		if month < 1 || month > 12 {
+			return DateRange{}, fmt.Errorf("%w: %q: no month %d", ErrInvalidDate, input, month)
+		}
+		return periodRange(PeriodMonth, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+
+	if match := quarterExpr.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		return periodRange(PeriodQuarter, time.Date(year, time.Month(quarter*3), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+
+	if yearExpr.MatchString(text) {
+		year, _ := strconv.Atoi(text)
+		return periodRange(PeriodYear, time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+
+	// Weekdays: "monday" is the latest monday up to today, "last monday" the one before today,
+	// and "next monday" the first one after today.
+	words := strings.Fields(text)
+	if len(words) <= 2 {
+		direction := ""
+		if len(words) == 2 {
+			direction = words[0]
+		}
+		weekday, err := ParseWeekday(words[len(words)-1])
+		if err == nil {
+			daysBack := (int(today.Weekday()) - int(weekday) + 7) % 7
+			switch direction {
+			case "", "this":
+				return singleDate(today.AddDate(0, 0, -daysBack)), nil
+			case "last":
+				if daysBack == 0 {
+					daysBack = 7
+				}
+				return singleDate(today.AddDate(0, 0, -daysBack)), nil
+			case "next":
+				return singleDate(today.AddDate(0, 0, 7-daysBack)), nil
+			}
+		}
+	}
+
+	return DateRange{}, fmt.Errorf("%w: %q (expected one of: %s)", ErrInvalidDate, input, DateInputHelp)
+}
+
+// ParseDate parses a date like ParseDateRange, and returns the first date of the range
+func ParseDate(input string, now time.Time) (time.Time, error) {
+	dateRange, err := ParseDateRange(input, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dateRange.From, nil
+}
+
+// ParsePeriodRange parses a named range relative to now, like "this-week", "last-month" or "next-year",
+// where the period may be any of the names for Period. Spaces may be used instead of dashes.
+// Anything else is parsed with ParseDateRange, so e.g. "2022-03" gives the whole of March 2022.
+func ParsePeriodRange(input string, now time.Time) (DateRange, error) {
+	text := strings.ToLower(strings.TrimSpace(input))
+	relation, name, found := strings.Cut(strings.Join(strings.Fields(strings.ReplaceAll(text, "-", " ")), " "), " ")
+	if found {
+		if period, err := ParsePeriod(name); err == nil {
+			current := period.Start(now)
+			switch relation {
+			case "this", "current":
+				return periodRange(period, current), nil
+			case "last", "previous":
+				return periodRange(period, current.AddDate(0, 0, -1)), nil
+			case "next":
+				return periodRange(period, period.Next(current)), nil
+			}
+		}
+	}
+	return ParseDateRange(input, now)
+}
@@ -0,0 +1,82 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateRange(t *testing.T) {
+	// A Wednesday, late in the day local time
+	now := time.Date(2026, time.April, 1, 23, 30, 0, 0, time.Local)
+	tests := []struct {
+		input string
+		from  time.Time
+		to    time.Time
+	}{
+		{"today", ymd(2026, 4, 1), ymd(2026, 4, 1)},
+		{" Yesterday ", ymd(2026, 3, 31), ymd(2026, 3, 31)},
+		{"tomorrow", ymd(2026, 4, 2), ymd(2026, 4, 2)},
+		{"2026-02-28", ymd(2026, 2, 28), ymd(2026, 2, 28)},
+		{"-3d", ymd(2026, 3, 29), ymd(2026, 3, 29)},
+		{"+1w", ymd(2026, 4, 8), ymd(2026, 4, 8)},
+		{"-2m", ymd(2026, 2, 1), ymd(2026, 2, 1)},
+		{"-1y", ymd(2025, 4, 1), ymd(2025, 4, 1)},
+		{"monday", ymd(2026, 3, 30), ymd(2026, 3, 30)},
+		{"wednesday", ymd(2026, 4, 1), ymd(2026, 4, 1)},
+		{"last wednesday", ymd(2026, 3, 25), ymd(2026, 3, 25)},
+		{"last  Monday", ymd(2026, 3, 30), ymd(2026, 3, 30)},
+		{"next mon", ymd(2026, 4, 6), ymd(2026, 4, 6)},
+		{"2026-W14", ymd(2026, 3, 30), ymd(2026, 4, 5)},
+		{"2021-w01", ymd(2021, 1, 4), ymd(2021, 1, 10)},
+		{"2020-W53", ymd(2020, 12, 28), ymd(2021, 1, 3)},
+		{"2026-03", ymd(2026, 3, 1), ymd(2026, 3, 31)},
+		{"2024-02", ymd(2024, 2, 1), ymd(2024, 2, 29)},
+		{"2026-Q2", ymd(2026, 4, 1), ymd(2026, 6, 30)},
+		{"2026", ymd(2026, 1, 1), ymd(2026, 12, 31)},
+	}
+	for _, tt := range tests {
+		dateRange, err := ParseDateRange(tt.input, now)
+		if assert.NoError(t, err, tt.input) {
+			assert.Equal(t, DateRange{From: tt.from, To: tt.to}, dateRange, tt.input)
+		}
+	}
+
+	for _, input := range []string{"", "someday", "3d", "2026-13", "2026-W54", "2021-W53", "2026-02-30", "last month"} {
+		_, err := ParseDateRange(input, now)
+		assert.ErrorIs(t, err, ErrInvalidDate, input)
+	}
+
+	date, err := ParseDate("2026-03", now)
+	assert.NoError(t, err)
+	assert.Equal(t, ymd(2026, 3, 1), date)
+}
+
+func TestParsePeriodRange(t *testing.T) {
+	now := time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		from  time.Time
+		to    time.Time
+	}{
+		{"this-week", ymd(2026, 3, 30), ymd(2026, 4, 5)},
+		{"last-week", ymd(2026, 3, 23), ymd(2026, 3, 29)},
+		{"last month", ymd(2026, 3, 1), ymd(2026, 3, 31)},
+		{"next-month", ymd(2026, 5, 1), ymd(2026, 5, 31)},
+		{"this-quarter", ymd(2026, 4, 1), ymd(2026, 6, 30)},
+		{"last-quarter", ymd(2026, 1, 1), ymd(2026, 3, 31)},
+		{"last-year", ymd(2025, 1, 1), ymd(2025, 12, 31)},
+		{"today", ymd(2026, 4, 1), ymd(2026, 4, 1)},
+		{"2026-03", ymd(2026, 3, 1), ymd(2026, 3, 31)},
+	}
+	for _, tt := range tests {
+		dateRange, err := ParsePeriodRange(tt.input, now)
+		if assert.NoError(t, err, tt.input) {
+			assert.Equal(t, DateRange{From: tt.from, To: tt.to}, dateRange, tt.input)
+		}
+	}
+
+	_, err := ParsePeriodRange("last-fortnight", now)
+	assert.ErrorIs(t, err, ErrInvalidDate)
+}
@@ -1,6 +1,7 @@
 package flex
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -9,13 +10,21 @@ import (
 )
 
 type DB struct {
-	FileName  string    `json:"-"`
-	Customers Customers `json:"customers"`
+	FileName        string    `json:"-"`
+	SchemaVersion   int       `json:"schema_version"` // set to SchemaVersion by EncodeDB
+	Customers       Customers `json:"customers"`
+	DefaultSchedule *Schedule `json:"default_schedule,omitempty"`
+	Calendar        Calendar  `json:"calendar,omitempty"`
+	// Workday is the length of a workday in the schedule used when neither a customer nor the DB
+	// has one, 0 meaning DefaultWorkdayLength. It comes from the Config, so it's not saved.
+	Workday time.Duration `json:"-"`
 }
 
-// IsEmpty returns true if its Customers field is nil, or its length i 0, false otherwise.
+// IsEmpty returns true if the DB has no Customers, no Calendar days and no DefaultSchedule, false otherwise.
 func (db *DB) IsEmpty() bool {
-	if db.Customers == nil || db.Customers.Len() == 0 {
+	if (db.Customers == nil || db.Customers.Len() == 0) &&
+		db.Calendar.Len() == 0 &&
+		db.DefaultSchedule == nil {
 		return true
 	}
 	return false
@@ -66,6 +75,39 @@ func (db *DB) AddCustomer(name string) (*Customer, error) {
 	return customer, nil
 }
 
+// getOrAddCustomer returns the default customer if customerName is blank,
+// otherwise the customer with the given name, which is added if it does not exist.
+func (db *DB) getOrAddCustomer(customerName string) *Customer {
+	if customerName == "" {
+		return db.GetDefaultCustomer()
+	}
+	customer, err := db.AddCustomer(customerName)
+	if err != nil {
+		log.Debug().Err(err).Send()
+	}
+	return customer
+}
+
+// SetWorkedForCustomer works like SetEntryForCustomer, but takes the amount of time actually worked
+// from the Worked field of the given Entry, and derives the flex Amount by subtracting the expected work
+// for that date according to the customers schedule.
+// Returns the Entry as it was set.
+func (db *DB) SetWorkedForCustomer(customerName string, entry Entry, overwrite bool) (*Entry, error) {
+	customer := db.getOrAddCustomer(customerName)
+	entry.Amount = entry.Worked - db.ExpectedWork(customer, entry.Date)
+	stored := customer.setEntry(entry, overwrite)
+	if stored == nil {
+		return nil, fmt.Errorf(
+			"failed to add %v worked on %s for customer: %s (overwrite: %t)",
+			entry.Worked,
+			entry.Date.Format(ShortDateFormat),
+			customer.Name,
+			overwrite,
+		)
+	}
+	return stored, nil
+}
+
 // GetTotalFlexForCustomer returns the total flex time for the given Customer if found,
 // or an error if not found.
 func (db *DB) GetTotalFlexForCustomer(customerName string) (time.Duration, error) {
@@ -95,24 +137,73 @@ func (db *DB) GetTotalFlexForAllCustomers() time.Duration {
 // If overwrite is true, it will replace any Entry with a matching date.
 // If overwrite is false, it will return an error if an Entry with a matching date is already present.
 func (db *DB) SetFlexForCustomer(customerName string, date time.Time, amount time.Duration, overwrite bool) error {
-	var err error
-	var customer *Customer
-	if customerName == "" {
-		customer = db.GetDefaultCustomer()
-	} else {
-		customer, err = db.AddCustomer(customerName)
-		if err != nil {
-			log.Debug().Err(err).Send()
-		}
-	}
-	if !customer.SetEntry(Entry{Date: date, Amount: amount}, overwrite) {
+	return db.SetEntryForCustomer(customerName, Entry{Date: date, Amount: amount}, overwrite)
+}
+
+// SetEntryForCustomer works like SetFlexForCustomer, but takes a whole Entry,
+// so that fields like Comment can be set as well.
+func (db *DB) SetEntryForCustomer(customerName string, entry Entry, overwrite bool) error {
+	customer := db.getOrAddCustomer(customerName)
+	if !customer.SetEntry(entry, overwrite) {
 		return fmt.Errorf(
 			"failed to add %v flex on %s for customer: %s (overwrite: %t)",
-			amount,
-			date.Format(ShortDateFormat),
+			entry.Amount,
+			entry.Date.Format(ShortDateFormat),
 			customer.Name,
 			overwrite,
 		)
 	}
 	return nil
 }
+
+// AddEntryForCustomer adds the given Entry to the customer with the given name, adding the customer if needed,
+// regardless of whether there already are entries for the same date.
+// If customerName is blank, it will use default customer.
+// Returns a pointer to the added Entry.
+func (db *DB) AddEntryForCustomer(customerName string, entry Entry) *Entry {
+	return db.getOrAddCustomer(customerName).AddEntry(entry)
+}
+
+// FindEntry returns the entry with the given ID, along with the customer it belongs to.
+// If customerName is blank, all customers are searched.
+func (db *DB) FindEntry(customerName, id string) (*Customer, *Entry, error) {
+	customers := db.Customers
+	if customerName != "" {
+		customer, err := db.GetCustomer(customerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		customers = Customers{customer}
+	}
+	for _, customer := range customers {
+		if entry, err := customer.GetEntryByID(id); err == nil {
+			return customer, entry, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrNoSuchEntryID, id)
+}
+
+// AssignEntryIDs gives an ID to every entry in the DB that is missing one, see Customer.AssignEntryIDs.
+// Returns how many IDs were assigned.
+func (db *DB) AssignEntryIDs() int {
+	assigned := 0
+	for _, customer := range db.Customers {
+		assigned += customer.AssignEntryIDs()
+	}
+	return assigned
+}
+
+// Clone returns a deep copy of the DB, made by encoding and decoding it
+func (db *DB) Clone() (*DB, error) {
+	data, err := json.Marshal(db)
+	if err != nil {
+		return nil, err
+	}
+	clone := NewDB()
+	if err = json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	clone.FileName = db.FileName
+	clone.Workday = db.Workday
+	return clone, nil
+}
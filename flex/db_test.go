@@ -244,3 +244,86 @@ func TestDBGetDefaultCustomerExpectFirst(t *testing.T) {
 	assert.NotNil(t, customer)
 	assert.Equal(t, "Customer2", customer.Name)
 }
+
+func TestDBSetWorkedForCustomer(t *testing.T) {
+	friday := time.Date(2021, time.December, 10, 0, 0, 0, 0, time.UTC)
+	saturday := friday.Add(24 * time.Hour)
+	db := NewDB()
+	customer, _ := db.AddCustomer("Customer1")
+	customer.Schedule = &Schedule{Friday: 6 * time.Hour}
+
+	entry, err := db.SetWorkedForCustomer("customer1", Entry{Date: friday, Worked: 9*time.Hour + 15*time.Minute}, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, 3*time.Hour+15*time.Minute, entry.Amount)
+		assert.Equal(t, 9*time.Hour+15*time.Minute, entry.Worked)
+	}
+
+	_, err = db.SetWorkedForCustomer("customer1", Entry{Date: friday, Worked: time.Hour}, false)
+	assert.Error(t, err)
+
+	_, err = db.SetWorkedForCustomer("customer1", Entry{Date: saturday, Worked: time.Hour}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4*time.Hour+15*time.Minute, customer.GetTotalFlex())
+}
+
+func TestDBIsEmptyWithCalendarOrSchedule(t *testing.T) {
+	db := &DB{
+		Calendar: Calendar{
+			{Date: time.Now(), Kind: DayHoliday},
+		},
+	}
+	assert.False(t, db.IsEmpty())
+
+	db = &DB{DefaultSchedule: StandardSchedule(DefaultWorkdayLength)}
+	assert.False(t, db.IsEmpty())
+}
+
+func TestDBSetEntryForCustomer(t *testing.T) {
+	today := time.Now()
+	db := NewDB()
+	err := db.SetEntryForCustomer("Customer1", Entry{Date: today, Amount: time.Hour, Comment: "release"}, false)
+	assert.NoError(t, err)
+	entry, err := db.Customers[0].GetEntry(today)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "release", entry.Comment)
+	}
+
+	err = db.SetEntryForCustomer("customer1", Entry{Date: today, Amount: time.Minute}, false)
+	assert.Error(t, err)
+}
+
+func TestDBFindEntry(t *testing.T) {
+	today := time.Now()
+	db := NewDB()
+	entry := db.AddEntryForCustomer("Customer1", Entry{Date: today, Amount: time.Hour})
+	db.AddEntryForCustomer("Customer2", Entry{Date: today, Amount: time.Minute})
+
+	customer, found, err := db.FindEntry("", entry.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, customer) {
+		assert.Equal(t, "Customer1", customer.Name)
+	}
+	assert.Equal(t, entry, found)
+
+	_, _, err = db.FindEntry("customer2", entry.ID)
+	assert.ErrorIs(t, err, ErrNoSuchEntryID)
+
+	_, _, err = db.FindEntry("customer3", entry.ID)
+	assert.ErrorIs(t, err, ErrNoSuchCustomer)
+}
+
+func TestDBClone(t *testing.T) {
+	db := NewDB()
+	db.FileName = "flextime.json"
+	db.SetFlexForCustomer("Customer1", time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), time.Hour, false)
+
+	clone, err := db.Clone()
+	assert.NoError(t, err)
+	assert.Equal(t, db, clone)
+
+	clone.Customers[0].Entries[0].Amount = time.Minute
+	assert.Equal(t, time.Hour, db.Customers[0].Entries[0].Amount)
+}
@@ -0,0 +1,128 @@
+package flex
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	clockDurationExpr   = regexp.MustCompile(`^(\d+):(\d+)$`)
+	hourMinuteExpr      = regexp.MustCompile(`^(\d+)h(\d+)$`)
+	integerDurationExpr = regexp.MustCompile(`^\d+$`)
+	decimalDurationExpr = regexp.MustCompile(`^(\d*)[.,](\d+)$`)
+	unitDurationExpr    = regexp.MustCompile(`^[\d.,]+[a-zµ]`)
+)
+
+// DurationInputHelp describes the input accepted by ParseFlexDuration, for use in help texts
+const DurationInputHelp = "-1:30, 1.5h, -0,75 (hours), +90 (minutes), 1h30, -1h30m"
+
+// ParseFlexDuration parses an amount of flex time, as written by people rather than programs.
+// An optional + or - sign may be followed by:
+//   - hours and minutes, like "1:30" or "0:05"
+//   - a number without unit, which is minutes if whole, like "90", and hours if decimal, like "1.5" or "0,75"
+//   - hours and minutes without unit for the minutes, like "1h30"
+//   - a Go duration, like "1h30m" or "1.5h", where a decimal comma may be used instead of a point
+//
+// Input that could reasonably mean more than one amount is refused rather than guessed at,
+// like "1:5" (1:05 or 1:50?) or "1,500" (1.5 hours or 1500 minutes?).
+func ParseFlexDuration(input string) (time.Duration, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(input), ""))
+	text = strings.ReplaceAll(text, "−", "-") // unicode minus, e.g. from copy and paste
+
+	sign := time.Duration(1)
+	unsigned := text
+	if strings.HasPrefix(unsigned, "+") || strings.HasPrefix(unsigned, "-") {
+		if unsigned[0] == '-' {
+			sign = -1
+		}
+		unsigned = unsigned[1:]
+	}
+	invalid := func(reason string) (time.Duration, error) {
+		return 0, fmt.Errorf("%w: %q: %s", ErrInvalidDuration, input, reason)
+	}
+
+	switch {
+	case unsigned == "":
+		return invalid("no amount")
+	case strings.HasPrefix(unsigned, "+") || strings.HasPrefix(unsigned, "-"):
+		return invalid("more than one sign")
+	}
+
+	if match := clockDurationExpr.FindStringSubmatch(unsigned); match != nil {
+		if len(match[2]) != 2 {
+			return invalid("minutes must have two digits, like 1:05 or 1:50")
+		}
+		duration, err := hoursAndMinutes(match[1], match[2])
+		if err != nil {
+			return invalid(err.Error())
+		}
+		return sign * duration, nil
+	}
+	if strings.Contains(unsigned, ":") {
+		return invalid("expected hours and minutes, like 1:30")
+	}
+
+	if match := hourMinuteExpr.FindStringSubmatch(unsigned); match != nil {
+		if len(match[2]) != 2 {
+			return invalid("minutes must have two digits, like 1h05 or 1h50")
+		}
+		duration, err := hoursAndMinutes(match[1], match[2])
+		if err != nil {
+			return invalid(err.Error())
+		}
+		return sign * duration, nil
+	}
+
+	if integerDurationExpr.MatchString(unsigned) {
+		minutes, err := strconv.Atoi(unsigned)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		return sign * time.Duration(minutes) * time.Minute, nil
+	}
+
+	if strings.Count(unsigned, ".")+strings.Count(unsigned, ",") > 1 {
+		return invalid("more than one decimal separator")
+	}
+
+	if match := decimalDurationExpr.FindStringSubmatch(unsigned); match != nil {
+		if strings.Contains(unsigned, ",") && len(match[2]) == 3 {
+			return invalid("the comma may be a decimal comma or a thousands separator, write e.g. 1,5 or 1500")
+		}
+		hours, err := strconv.ParseFloat(match[1]+"."+match[2], 64)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		return sign * time.Duration(math.Round(hours*float64(time.Hour))), nil
+	}
+
+	if unitDurationExpr.MatchString(unsigned) {
+		duration, err := time.ParseDuration(strings.ReplaceAll(unsigned, ",", "."))
+		if err != nil {
+			return invalid("expected a duration with units, like 1h30m or 1.5h")
+		}
+		return sign * duration, nil
+	}
+
+	return invalid("expected one of: " + DurationInputHelp)
+}
+
+// hoursAndMinutes returns the duration of the given whole hours and minutes, which must be digits only
+func hoursAndMinutes(hours, minutes string) (time.Duration, error) {
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, err
+	}
+	if m >= 60 {
+		return 0, fmt.Errorf("%d minutes is an hour or more", m)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
@@ -0,0 +1,68 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlexDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"1:30", 90 * time.Minute},
+		{"-1:30", -90 * time.Minute},
+		{"+0:05", 5 * time.Minute},
+		{"10:00", 10 * time.Hour},
+		{"90", 90 * time.Minute},
+		{"+90", 90 * time.Minute},
+		{"-15", -15 * time.Minute},
+		{"1.5", 90 * time.Minute},
+		{"-0,75", -45 * time.Minute},
+		{",5", 30 * time.Minute},
+		{"1.25", 75 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"-1,5h", -90 * time.Minute},
+		{"1h30", 90 * time.Minute},
+		{"-1h05", -65 * time.Minute},
+		{"-1h30m", -90 * time.Minute},
+		{" 45m ", 45 * time.Minute},
+		{"- 30m", -30 * time.Minute},
+		{"−2h", -2 * time.Hour},
+		{"1.500", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		duration, err := ParseFlexDuration(tt.input)
+		if assert.NoError(t, err, tt.input) {
+			assert.Equal(t, tt.expected, duration, tt.input)
+		}
+	}
+}
+
+func TestParseFlexDurationInvalid(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"", "no amount"},
+		{"-", "no amount"},
+		{"--1h", "more than one sign"},
+		{"1:5", "two digits"},
+		{"1:60", "an hour or more"},
+		{"1:30:00", "hours and minutes"},
+		{"1h5", "two digits"},
+		{"1,500", "thousands separator"},
+		{"1.5.0", "more than one decimal separator"},
+		{"1,5.0", "more than one decimal separator"},
+		{"1x", "with units"},
+		{"abc", "expected one of"},
+	}
+	for _, tt := range tests {
+		_, err := ParseFlexDuration(tt.input)
+		if assert.ErrorIs(t, err, ErrInvalidDuration, tt.input) {
+			assert.Contains(t, err.Error(), tt.reason, tt.input)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package flex
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DurationFormat is how amounts of time are formatted for people to read
+type DurationFormat uint8
+
+const (
+	DurationFormatGo       DurationFormat = iota // like time.Duration.String, e.g. -12h45m0s
+	DurationFormatClock                          // signed hours and minutes, e.g. -12:45
+	DurationFormatDecimal                        // decimal hours, e.g. -12.75
+	DurationFormatWorkdays                       // workdays, hours and minutes, e.g. -1d 4h45m
+	DurationFormatMinutes                        // whole minutes, e.g. -765
+)
+
+var durationFormatNames = map[DurationFormat]string{
+	DurationFormatGo:       "go",
+	DurationFormatClock:    "clock",
+	DurationFormatDecimal:  "decimal",
+	DurationFormatWorkdays: "workdays",
+	DurationFormatMinutes:  "minutes",
+}
+
+// ParseDurationFormat returns the DurationFormat matching the given name
+func ParseDurationFormat(name string) (DurationFormat, error) {
+	for format, formatName := range durationFormatNames {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return DurationFormatGo, fmt.Errorf("%w: %q", ErrInvalidDurationFormat, name)
+}
+
+// DurationFormatOptions returns the valid names for DurationFormat, for use in help texts
+func DurationFormatOptions() string {
+	return strings.Join(
+		[]string{
+			DurationFormatGo.String(),
+			DurationFormatClock.String(),
+			DurationFormatDecimal.String(),
+			DurationFormatWorkdays.String(),
+			DurationFormatMinutes.String(),
+		},
+		", ",
+	)
+}
+
+func (format DurationFormat) String() string {
+	if name, ok := durationFormatNames[format]; ok {
+		return name
+	}
+	return fmt.Sprintf("DurationFormat(%d)", format)
+}
+
+// Format returns d formatted according to the format. Workday is the length of a workday,
+// only used by DurationFormatWorkdays, where 0 means DefaultWorkdayLength.
+// All formats but DurationFormatGo round to whole minutes, or hundredths of an hour for decimal.
+func (format DurationFormat) Format(d, workday time.Duration) string {
+	switch format {
+	case DurationFormatClock:
+		return formatClock(d)
+	case DurationFormatDecimal:
+		return fmt.Sprintf("%.2f", d.Hours())
+	case DurationFormatWorkdays:
+		return formatWorkdays(d, workday)
+	case DurationFormatMinutes:
+		return fmt.Sprintf("%d", int64(math.Round(d.Minutes())))
+	default:
+		return d.String()
+	}
+}
+
+// formatClock returns the duration as signed hours and minutes, e.g. "+1:30"
+func formatClock(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%s%d:%02d", sign, int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatWorkdays returns the duration as whole workdays of the given length, then hours and minutes,
+// leaving out parts that are zero, e.g. "-1d 4h45m", "2d" or "30m"
+func formatWorkdays(d, workday time.Duration) string {
+	if workday <= 0 {
+		workday = DefaultWorkdayLength
+	}
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Minute)
+	if d == 0 {
+		return "0m"
+	}
+	days := d / workday
+	d -= days * workday
+	hours := d / time.Hour
+	minutes := (d - hours*time.Hour) / time.Minute
+
+	parts := make([]string, 0, 2)
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	clock := ""
+	if hours > 0 {
+		clock += fmt.Sprintf("%dh", hours)
+	}
+	if minutes > 0 {
+		clock += fmt.Sprintf("%dm", minutes)
+	}
+	if clock != "" {
+		parts = append(parts, clock)
+	}
+	return sign + strings.Join(parts, " ")
+}
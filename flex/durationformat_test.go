@@ -0,0 +1,45 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDurationFormat(t *testing.T) {
+	format, err := ParseDurationFormat("Workdays")
+	assert.NoError(t, err)
+	assert.Equal(t, DurationFormatWorkdays, format)
+
+	_, err = ParseDurationFormat("fortnights")
+	assert.ErrorIs(t, err, ErrInvalidDurationFormat)
+}
+
+func TestDurationFormatFormat(t *testing.T) {
+	d := -(12*time.Hour + 45*time.Minute)
+	tests := []struct {
+		format   DurationFormat
+		d        time.Duration
+		workday  time.Duration
+		expected string
+	}{
+		{DurationFormatGo, d, 0, "-12h45m0s"},
+		{DurationFormatClock, d, 0, "-12:45"},
+		{DurationFormatClock, 90 * time.Minute, 0, "+1:30"},
+		{DurationFormatDecimal, d, 0, "-12.75"},
+		{DurationFormatDecimal, 20 * time.Minute, 0, "0.33"},
+		{DurationFormatMinutes, d, 0, "-765"},
+		{DurationFormatMinutes, 150 * time.Second, 0, "3"},
+		{DurationFormatWorkdays, d, 8 * time.Hour, "-1d 4h45m"},
+		{DurationFormatWorkdays, d, 0, "-1d 4h45m"},
+		{DurationFormatWorkdays, d, 7*time.Hour + 30*time.Minute, "-1d 5h15m"},
+		{DurationFormatWorkdays, 16 * time.Hour, 8 * time.Hour, "2d"},
+		{DurationFormatWorkdays, 8*time.Hour + 30*time.Minute, 8 * time.Hour, "1d 30m"},
+		{DurationFormatWorkdays, -30 * time.Minute, 8 * time.Hour, "-30m"},
+		{DurationFormatWorkdays, 20 * time.Second, 8 * time.Hour, "0m"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.format.Format(tt.d, tt.workday), "%s %v", tt.format, tt.d)
+	}
+}
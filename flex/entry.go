@@ -1,31 +1,67 @@
 package flex
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
 	"sort"
 	"time"
 )
 
-// An Entry is the unit for recording flex time +/- for a given date
+// An Entry is the unit for recording flex time +/- for a given date.
+// There may be several entries for the same date, told apart by their ID.
 type Entry struct {
+	ID      string        `json:"id,omitempty"`
 	Date    time.Time     `json:"date,omitempty"`
 	Amount  time.Duration `json:"amount,omitempty"`
 	Comment string        `json:"comment,omitempty"`
+	Worked  time.Duration `json:"worked,omitempty"`
 }
 
 type Entries []*Entry
 type EntriesByDate Entries
 type EntriesByAmount Entries
 
-// MatchDate returns true of the date for the two Entries match on year, month and day, false otherwise
-func (entry Entry) MatchDate(otherEntry Entry) bool {
-	if entry.Date.Year() == otherEntry.Date.Year() &&
-		entry.Date.Month() == otherEntry.Date.Month() &&
-		entry.Date.Day() == otherEntry.Date.Day() {
+// NewEntryID returns a new random ID for an Entry
+func NewEntryID() string {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// DateOf returns midnight UTC of the calendar date of the given time,
+// which is how dates are stored in Entries.
+func DateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// SameDate returns true if the two times match on year, month and day, false otherwise
+func SameDate(t1, t2 time.Time) bool {
+	if t1.Year() == t2.Year() &&
+		t1.Month() == t2.Month() &&
+		t1.Day() == t2.Day() {
 		return true
 	}
 	return false
 }
 
+// MatchDate returns true of the date for the two Entries match on year, month and day, false otherwise
+func (entry Entry) MatchDate(otherEntry Entry) bool {
+	return SameDate(entry.Date, otherEntry.Date)
+}
+
+// AppendComment adds the given text to the end of the Comment, separated by "; " if there already is a comment
+func (entry *Entry) AppendComment(text string) {
+	if entry.Comment == "" {
+		entry.Comment = text
+		return
+	}
+	entry.Comment += "; " + text
+}
+
 // WithinDateRange returns true if the Entry is within the two given dates, inclusive, false otherwise.
 func (entry Entry) WithinDateRange(from, to time.Time) bool {
 	if entry.Date.Before(from) || entry.Date.After(to) {
@@ -45,6 +81,17 @@ func (entries Entries) FilterByDateRange(from, to time.Time) Entries {
 	return filteredEntries
 }
 
+// FilterByComment returns a new Entries slice with the entries where the comment matches the given expression.
+func (entries Entries) FilterByComment(expr *regexp.Regexp) Entries {
+	filteredEntries := make(Entries, 0)
+	for _, entry := range entries {
+		if expr.MatchString(entry.Comment) {
+			filteredEntries = append(filteredEntries, entry)
+		}
+	}
+	return filteredEntries
+}
+
 // FilterByNotInDateRange returns a new Entries slice with the entries that are not within the given range.
 func (entries Entries) FilterByNotInDateRange(from, to time.Time) Entries {
 	filteredEntries := make(Entries, 0)
@@ -86,7 +133,12 @@ func (entries Entries) LastDate() (*time.Time, error) {
 
 // IndexOf returns the index of the matching entry, if found,
 // or -1 if not found.
+// If the given entry has an ID, it is matched on ID, otherwise on date,
+// in which case the index of the first entry for that date is returned.
 func (entries Entries) IndexOf(entry Entry) int {
+	if entry.ID != "" {
+		return entries.IndexOfID(entry.ID)
+	}
 	for idx := range entries {
 		if entry.MatchDate(*entries[idx]) {
 			return idx
@@ -95,6 +147,27 @@ func (entries Entries) IndexOf(entry Entry) int {
 	return -1
 }
 
+// IndexOfID returns the index of the entry with the given ID, or -1 if not found.
+func (entries Entries) IndexOfID(id string) int {
+	for idx := range entries {
+		if entries[idx].ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// FilterByDate returns a new Entries slice with all the entries for the given date.
+func (entries Entries) FilterByDate(date time.Time) Entries {
+	filteredEntries := make(Entries, 0)
+	for _, entry := range entries {
+		if SameDate(entry.Date, date) {
+			filteredEntries = append(filteredEntries, entry)
+		}
+	}
+	return filteredEntries
+}
+
 // Delete removes a matching entry from the Entries slice.
 // Returns true if match found and removed, false if not.
 func (entries *Entries) Delete(entry Entry) bool {
@@ -110,10 +183,23 @@ func (entries *Entries) Delete(entry Entry) bool {
 	return true
 }
 
-// DeleteByDate removes an entry with a matching date from the Entries slice.
-// Returns true if match found and deleted, false if not.
+// DeleteByDate removes all entries with a matching date from the Entries slice.
+// Returns true if any match found and deleted, false if not.
 func (entries *Entries) DeleteByDate(date time.Time) bool {
-	return entries.Delete(Entry{Date: date})
+	deleted := false
+	for entries.Delete(Entry{Date: date}) {
+		deleted = true
+	}
+	return deleted
+}
+
+// DeleteByID removes the entry with the given ID from the Entries slice.
+// Returns true if found and deleted, false if not.
+func (entries *Entries) DeleteByID(id string) bool {
+	if id == "" {
+		return false
+	}
+	return entries.Delete(Entry{ID: id})
 }
 
 // GetTotalFlex returns the sum of the Amount fields in all Entries
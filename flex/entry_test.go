@@ -1,6 +1,7 @@
 package flex
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -358,3 +359,91 @@ func TestSortEntriesByAmountDescending(t *testing.T) {
 	assert.Equal(t, entry2, entries[1])
 	assert.Equal(t, entry3, entries[0])
 }
+
+func TestEntryAppendComment(t *testing.T) {
+	entry := Entry{}
+	entry.AppendComment("release")
+	assert.Equal(t, "release", entry.Comment)
+	entry.AppendComment("hotfix")
+	assert.Equal(t, "release; hotfix", entry.Comment)
+}
+
+func TestEntriesFilterByComment(t *testing.T) {
+	entries := Entries{
+		{Amount: 1, Comment: "Release night"},
+		{Amount: 2},
+		{Amount: 3, Comment: "hotfix after release"},
+	}
+	filtered := entries.FilterByComment(regexp.MustCompile("(?i)^release"))
+	if assert.Equal(t, 1, filtered.Len()) {
+		assert.Equal(t, time.Duration(1), filtered[0].Amount)
+	}
+	filtered = entries.FilterByComment(regexp.MustCompile("release"))
+	if assert.Equal(t, 1, filtered.Len()) {
+		assert.Equal(t, time.Duration(3), filtered[0].Amount)
+	}
+	assert.Equal(t, 3, entries.FilterByComment(regexp.MustCompile("")).Len())
+}
+
+func TestNewEntryID(t *testing.T) {
+	id1 := NewEntryID()
+	id2 := NewEntryID()
+	assert.Len(t, id1, 8)
+	assert.NotEqual(t, id1, id2)
+}
+
+func TestEntriesIndexOfID(t *testing.T) {
+	today := time.Now()
+	entries := Entries{
+		{ID: "a", Date: today},
+		{ID: "b", Date: today},
+	}
+	assert.Equal(t, 1, entries.IndexOfID("b"))
+	assert.Equal(t, -1, entries.IndexOfID("c"))
+	assert.Equal(t, 1, entries.IndexOf(Entry{ID: "b"}))
+	assert.Equal(t, 0, entries.IndexOf(Entry{Date: today}))
+}
+
+func TestEntriesFilterByDate(t *testing.T) {
+	today := time.Now()
+	yesterday := today.Add(-24 * time.Hour)
+	entries := Entries{
+		{ID: "a", Date: today},
+		{ID: "b", Date: yesterday},
+		{ID: "c", Date: today},
+	}
+	filtered := entries.FilterByDate(today)
+	if assert.Equal(t, 2, filtered.Len()) {
+		assert.Equal(t, "a", filtered[0].ID)
+		assert.Equal(t, "c", filtered[1].ID)
+	}
+}
+
+func TestEntriesDeleteByDateWithSeveralEntries(t *testing.T) {
+	today := time.Now()
+	yesterday := today.Add(-24 * time.Hour)
+	entries := Entries{
+		{ID: "a", Date: today},
+		{ID: "b", Date: yesterday},
+		{ID: "c", Date: today},
+	}
+	assert.True(t, entries.DeleteByDate(today))
+	if assert.Equal(t, 1, entries.Len()) {
+		assert.Equal(t, "b", entries[0].ID)
+	}
+	assert.False(t, entries.DeleteByDate(today))
+}
+
+func TestEntriesDeleteByID(t *testing.T) {
+	today := time.Now()
+	entries := Entries{
+		{ID: "a", Date: today},
+		{ID: "b", Date: today},
+	}
+	assert.False(t, entries.DeleteByID(""))
+	assert.False(t, entries.DeleteByID("c"))
+	assert.True(t, entries.DeleteByID("a"))
+	if assert.Equal(t, 1, entries.Len()) {
+		assert.Equal(t, "b", entries[0].ID)
+	}
+}
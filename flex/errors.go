@@ -3,11 +3,44 @@ package flex
 import "errors"
 
 var (
-	ErrNoEntry          = errors.New("no entry for given date")
-	ErrNoEntries        = errors.New("no entries for customer")
-	ErrNoSuchCustomer   = errors.New("no such customer")
-	ErrCustomerExists   = errors.New("customer already exists")
-	ErrNilCustomer      = errors.New("customer is nil")
-	ErrInvalidJSONInput = errors.New("invalid JSON input")
-	ErrEmptyDB          = errors.New("empty flex database")
+	ErrNoEntry               = errors.New("no entry for given date")
+	ErrNoEntries             = errors.New("no entries for customer")
+	ErrNoSuchEntryID         = errors.New("no entry with given id")
+	ErrAmbiguousDate         = errors.New("several entries for given date")
+	ErrNoSuchCustomer        = errors.New("no such customer")
+	ErrCustomerExists        = errors.New("customer already exists")
+	ErrInvalidCustomerName   = errors.New("invalid customer name")
+	ErrMergeConflict         = errors.New("both customers have entries for the same dates")
+	ErrInvalidMergePolicy    = errors.New("invalid merge policy")
+	ErrTransferConflict      = errors.New("conflicting entries")
+	ErrInvalidTransferPolicy = errors.New("invalid transfer policy")
+	ErrNilCustomer           = errors.New("customer is nil")
+	ErrInvalidJSONInput      = errors.New("invalid JSON input")
+	ErrEmptyDB               = errors.New("empty flex database")
+	ErrLocked                = errors.New("database is locked by another process")
+	ErrInvalidJournal        = errors.New("invalid journal")
+	ErrJournalConflict       = errors.New("database was changed outside of the journal")
+	ErrNothingToUndo         = errors.New("nothing to undo")
+	ErrNothingToRedo         = errors.New("nothing to redo")
+	ErrSessionRunning        = errors.New("session already running")
+	ErrNoSession             = errors.New("no running session")
+	ErrInvalidSession        = errors.New("session stops before it starts")
+	ErrEntryIsManual         = errors.New("entry was set manually")
+	ErrInvalidWeekday        = errors.New("invalid weekday")
+	ErrInvalidSchedule       = errors.New("invalid schedule")
+	ErrInvalidDayKind        = errors.New("invalid day kind")
+	ErrNoCalendarDay         = errors.New("no calendar day for given date")
+	ErrInvalidICalendar      = errors.New("invalid iCalendar input")
+	ErrInvalidPeriod         = errors.New("invalid period")
+	ErrInvalidDate           = errors.New("invalid date")
+	ErrInvalidDuration       = errors.New("invalid duration")
+	ErrInvalidDurationFormat = errors.New("invalid duration format")
+	ErrInvalidConfig         = errors.New("invalid config file")
+	ErrInvalidAmountFormat   = errors.New("invalid amount format")
+	ErrInvalidCSVMapping     = errors.New("invalid CSV column mapping")
+	ErrInvalidTimewarrior    = errors.New("invalid Timewarrior input")
+	ErrInvalidTimeclock      = errors.New("invalid timeclock input")
+	ErrInvalidSchemaVersion  = errors.New("invalid schema version")
+	ErrOutdatedSchemaVersion = errors.New("outdated schema version")
+	ErrUnknownField          = errors.New("unknown field")
 )
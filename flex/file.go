@@ -0,0 +1,140 @@
+package flex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// BackupFileName returns the name of the given backup number for fileName, e.g. "flextime.json.1"
+func BackupFileName(fileName string, number int) string {
+	return fmt.Sprintf("%s.%d", fileName, number)
+}
+
+// RotateBackups shifts existing backups of fileName one step up, dropping the oldest,
+// and then keeps the current content of fileName as backup number 1.
+// At most the given number of backups are kept. If backups is 0 or less, nothing is done.
+// It is not an error if fileName does not exist yet.
+func RotateBackups(fileName string, backups int) error {
+	if backups <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(fileName); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	for number := backups - 1; number > 0; number-- {
+		err := os.Rename(BackupFileName(fileName, number), BackupFileName(fileName, number+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	first := BackupFileName(fileName, 1)
+	if err := os.Remove(first); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// A hard link keeps the old content in place after the new file is renamed over fileName,
+	// without copying. Fall back to copying where links are not supported.
+	if err := os.Link(fileName, first); err == nil {
+		return nil
+	}
+	return copyFile(fileName, first)
+}
+
+func copyFile(source, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WriteFileAtomic calls write with a temporary file in the same directory as fileName,
+// syncs it to disk, and then renames it to fileName. If anything fails, fileName is left untouched.
+// Before the rename, backups of the existing file are rotated, see RotateBackups.
+func WriteFileAtomic(fileName string, backups int, write func(io.Writer) error) error {
+	dir, base := filepath.Split(fileName)
+	if dir == "" {
+		dir = "."
+	}
+	tmpFile, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmpFile.Name()
+	fail := func(err error) error {
+		tmpFile.Close()
+		os.Remove(tmpName)
+		return err
+	}
+
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(fileName); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err = tmpFile.Chmod(perm); err != nil {
+		return fail(err)
+	}
+	if err = write(tmpFile); err != nil {
+		return fail(err)
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return fail(err)
+	}
+	if err = tmpFile.Close(); err != nil {
+		return fail(err)
+	}
+	if err = RotateBackups(fileName, backups); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err = os.Rename(tmpName, fileName); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename in the given directory durable, where the platform supports it
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// SaveDB encodes the DB to its FileName, or to stdout if FileName is "-".
+// Files are written with WriteFileAtomic, keeping the given number of backups.
+func SaveDB(db *DB, backups int) error {
+	if db == nil {
+		return fmt.Errorf("refusing to save nil DB")
+	}
+	if db.FileName == "-" {
+		return EncodeDB(db, os.Stdout)
+	}
+	return WriteFileAtomic(db.FileName, backups, func(writer io.Writer) error {
+		return EncodeDB(db, writer)
+	})
+}
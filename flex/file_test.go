@@ -0,0 +1,95 @@
+package flex
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeString(content string) func(io.Writer) error {
+	return func(writer io.Writer) error {
+		_, err := io.WriteString(writer, content)
+		return err
+	}
+}
+
+func readString(t *testing.T, fileName string) string {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return string(data)
+}
+
+func TestBackupFileName(t *testing.T) {
+	assert.Equal(t, "flex.json.2", BackupFileName("flex.json", 2))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "flex.json")
+
+	assert.NoError(t, WriteFileAtomic(fileName, 2, writeString("1")))
+	assert.Equal(t, "1", readString(t, fileName))
+	assert.NoFileExists(t, BackupFileName(fileName, 1))
+
+	for _, content := range []string{"2", "3", "4"} {
+		assert.NoError(t, WriteFileAtomic(fileName, 2, writeString(content)))
+	}
+	assert.Equal(t, "4", readString(t, fileName))
+	assert.Equal(t, "3", readString(t, BackupFileName(fileName, 1)))
+	assert.Equal(t, "2", readString(t, BackupFileName(fileName, 2)))
+	assert.NoFileExists(t, BackupFileName(fileName, 3))
+}
+
+func TestWriteFileAtomicWhenWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "flex.json")
+	assert.NoError(t, WriteFileAtomic(fileName, 1, writeString("good")))
+
+	errWrite := errors.New("write failed")
+	err := WriteFileAtomic(fileName, 1, func(writer io.Writer) error {
+		io.WriteString(writer, "partial")
+		return errWrite
+	})
+	assert.ErrorIs(t, err, errWrite)
+	assert.Equal(t, "good", readString(t, fileName))
+	assert.NoFileExists(t, BackupFileName(fileName, 1))
+
+	// no temporary files are left behind
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+}
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "flex.json")
+	assert.NoError(t, os.WriteFile(fileName, []byte("old"), 0600))
+	assert.NoError(t, WriteFileAtomic(fileName, 0, writeString("new")))
+	info, err := os.Stat(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, BackupFileName(fileName, 1))
+}
+
+func TestSaveDB(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "flex.json")
+	db := NewDB()
+	db.FileName = fileName
+	assert.NoError(t, db.SetFlexForCustomer("Customer1", time.Now(), time.Hour, false))
+	assert.NoError(t, SaveDB(db, DefaultBackupCount))
+
+	file, err := os.Open(fileName)
+	assert.NoError(t, err)
+	defer file.Close()
+	decoded, err := DecodeDB(file)
+	assert.NoError(t, err)
+	total, err := decoded.GetTotalFlexForCustomer("customer1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, total)
+
+	assert.Error(t, SaveDB(nil, 0))
+}
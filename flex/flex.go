@@ -2,9 +2,12 @@ package flex
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 // NewDB initializes and returns a new, empty DB instance
@@ -42,21 +45,128 @@ func GetFileOrStdoutForWriting(fileName string) (*os.File, error) {
 	return file, nil
 }
 
-// EncodeDB encodes the given DB as JSON to the given writer
+// EncodeDB encodes the given DB as JSON to the given writer, at the current SchemaVersion
 func EncodeDB(db *DB, writer io.Writer) error {
+	db.SchemaVersion = SchemaVersion
 	return json.NewEncoder(writer).Encode(db)
 }
 
 // DecodeDB tries to decode JSON input from the given reader
-// into a new DB instance
+// into a new DB instance.
+// Input from older schema versions is refused with ErrOutdatedSchemaVersion, see MigrateJSON for upgrading it.
+// Fields that are not part of the DB are an error, ErrUnknownField, as they'd be lost on the next save,
+// see DecodeDBLenient for reading such input anyway.
+// Entries without an ID, e.g. added by hand, are given one, the same each time the input is read.
 func DecodeDB(reader io.Reader) (*DB, error) {
-	db := &DB{}
-	err := json.NewDecoder(reader).Decode(db)
-	if err != nil && !errors.Is(err, io.EOF) {
+	db, unknown, err := DecodeDBLenient(reader)
+	if err != nil {
 		return nil, err
 	}
-	if db.IsEmpty() {
-		return nil, ErrEmptyDB
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, strings.Join(unknown, ", "))
 	}
 	return db, nil
 }
+
+// DecodeDBLenient works like DecodeDB, but ignores fields that are not part of the DB,
+// and returns their paths, like "customers[0].flex_entries[2].amout", instead of failing.
+// Input from an older schema version is returned as it was read, along with ErrOutdatedSchemaVersion,
+// so that it can be compared with what migrating it gives, but is not meant to be used otherwise.
+func DecodeDBLenient(reader io.Reader) (*DB, []string, error) {
+	document, err := decodeDocument(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	version, err := schemaVersionOf(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	db := &DB{}
+	if err = json.Unmarshal(data, db); err != nil {
+		return nil, nil, err
+	}
+	if db.IsEmpty() {
+		return nil, nil, ErrEmptyDB
+	}
+	if version < SchemaVersion {
+		return db, nil, fmt.Errorf(
+			"%w: %d is older than %d, migrate the file to read it",
+			ErrOutdatedSchemaVersion,
+			version,
+			SchemaVersion,
+		)
+	}
+	unknown := unknownFields(document, reflect.TypeOf(db), "")
+	db.AssignEntryIDs()
+	return db, unknown, nil
+}
+
+// unknownFields returns the paths of the fields in the decoded JSON value that the given type has no field for.
+// Types that decode themselves, like time.Time, are taken to know all of their input.
+func unknownFields(value any, typ reflect.Type, path string) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+	unknown := make([]string, 0)
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		fields := make(map[string]reflect.Type)
+		for idx := 0; idx < typ.NumField(); idx++ {
+			field := typ.Field(idx)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = field.Type
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			fieldType, found := fields[key]
+			if !found {
+				// encoding/json matches field names case insensitively
+				for name, candidate := range fields {
+					if strings.EqualFold(name, key) {
+						fieldType, found = candidate, true
+						break
+					}
+				}
+			}
+			if !found {
+				unknown = append(unknown, fieldPath)
+				continue
+			}
+			unknown = append(unknown, unknownFields(object[key], fieldType, fieldPath)...)
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+		for idx, item := range list {
+			unknown = append(unknown, unknownFields(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, idx))...)
+		}
+	}
+	return unknown
+}
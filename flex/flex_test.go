@@ -101,7 +101,9 @@ func TestEncodeDB(t *testing.T) {
 	db := &DB{FileName: "flex.json", Customers: Customers{&c1, &c2}}
 
 	expected := fmt.Sprintf(
-		"{%q:[{%q:%q,%q:[{%q:%s,%q:1}]},{%q:%q,%q:[{%q:%s,%q:1}]}]}\n",
+		"{%q:%d,%q:[{%q:%q,%q:[{%q:%s,%q:1}]},{%q:%q,%q:[{%q:%s,%q:1}]}]}\n",
+		"schema_version",
+		SchemaVersion,
 		"customers",
 		"customer_name",
 		"Customer1",
@@ -153,7 +155,9 @@ func TestDecodeDB(t *testing.T) {
 		t.Errorf("Unable to generate JSON date: %v", err)
 	}
 	jsonInput := fmt.Sprintf(
-		"{%q:[{%q:%q,%q:[{%q:%s,%q:1}]},{%q:%q,%q:[{%q:%s,%q:1}]}]}\n",
+		"{%q:%d,%q:[{%q:%q,%q:[{%q:%s,%q:1}]},{%q:%q,%q:[{%q:%s,%q:1}]}]}\n",
+		"schema_version",
+		SchemaVersion,
 		"customers",
 		"customer_name",
 		"Customer1",
@@ -193,4 +197,6 @@ func TestDecodeDB(t *testing.T) {
 		time.Duration(1),
 		db.Customers[1].Entries[0].Amount,
 	)
+	assert.NotEmpty(t, db.Customers[0].Entries[0].ID)
+	assert.NotEmpty(t, db.Customers[1].Entries[0].ID)
 }
@@ -0,0 +1,276 @@
+package flex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	iCalDateFormat     = "20060102"
+	iCalDateTimeFormat = "20060102T150405"
+)
+
+// unfoldICalLines reads all content lines from the given reader,
+// joining lines that are folded according to RFC 5545.
+func unfoldICalLines(reader io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitICalLine splits a content line into property name, parameters and value.
+// Parameter names are upper case, and quotes around parameter values are removed.
+func splitICalLine(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		param, paramValue, _ := strings.Cut(part, "=")
+		params[strings.ToUpper(param)] = strings.Trim(paramValue, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseICalDate parses a DATE or DATE-TIME value, and returns the time along with true if it's a DATE.
+// A DATE-TIME is in UTC if it ends with Z, or else in the zone of its TZID parameter,
+// or in the given location if it has neither, and is returned in the given location.
+func parseICalDate(value string, params map[string]string, location *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || !strings.Contains(value, "T") {
+		t, err := time.Parse(iCalDateFormat, value)
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%w: %v", ErrInvalidICalendar, err)
+		}
+		return t, true, nil
+	}
+
+	zone := location
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		zone = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	case params["TZID"] != "":
+		var err error
+		if zone, err = time.LoadLocation(params["TZID"]); err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: unknown TZID %q", ErrInvalidICalendar, params["TZID"])
+		}
+	}
+	t, err := time.ParseInLocation(iCalDateTimeFormat, value, zone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %v", ErrInvalidICalendar, err)
+	}
+	return t.In(location), false, nil
+}
+
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(
+		`\n`, " ",
+		`\N`, " ",
+		`\,`, ",",
+		`\;`, ";",
+		`\\`, `\`,
+	).Replace(value)
+}
+
+// ParseICalendar reads VEVENTs from iCalendar (.ics) input, and returns a Calendar with a day
+// of the given kind for each date covered by an event. The SUMMARY of the event is used as name.
+// Events spanning several days give one CalendarDay per day. Recurrence rules are not expanded.
+// Events with times count for the dates they cover in the given location, see parseICalDate.
+// The DTEND of an event is exclusive when it's a DATE, as is a DATE-TIME at midnight.
+func ParseICalendar(reader io.Reader, kind DayKind, location *time.Location) (Calendar, error) {
+	lines, err := unfoldICalLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := make(Calendar, 0)
+	inEvent := false
+	var start, end time.Time
+	var endIsDate bool
+	var summary string
+
+	for idx, line := range lines {
+		name, params, value := splitICalLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			start, end, endIsDate, summary = time.Time{}, time.Time{}, false, ""
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !inEvent {
+				return nil, fmt.Errorf("%w: content line %d: END without BEGIN", ErrInvalidICalendar, idx+1)
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("%w: content line %d: event without DTSTART", ErrInvalidICalendar, idx+1)
+			}
+			first := DateOf(start)
+			last := first
+			switch {
+			case !end.After(start):
+			case endIsDate:
+				last = DateOf(end).AddDate(0, 0, -1)
+			default:
+				// the last day is the one of the last moment of the event
+				last = DateOf(end.Add(-time.Nanosecond))
+			}
+			for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+				calendar.Set(CalendarDay{Date: date, Kind: kind, Name: summary}, true)
+			}
+		case !inEvent:
+			continue
+		case name == "DTSTART":
+			if start, _, err = parseICalDate(value, params, location); err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			if end, endIsDate, err = parseICalDate(value, params, location); err != nil {
+				return nil, err
+			}
+		case name == "SUMMARY":
+			summary = unescapeICalText(value)
+		}
+	}
+
+	if inEvent {
+		return nil, fmt.Errorf("%w: unterminated event", ErrInvalidICalendar)
+	}
+
+	calendar.Sort()
+	return calendar, nil
+}
+
+func escapeICalText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// foldICalLine splits a content line into lines of at most 75 octets, as RFC 5545 requires,
+// without splitting any UTF-8 sequence, and ends each with CRLF
+func foldICalLine(line string) string {
+	builder := strings.Builder{}
+	width := 0
+	for _, char := range line {
+		size := utf8.RuneLen(char)
+		if width+size > 75 {
+			builder.WriteString("\r\n ")
+			width = 1
+		}
+		builder.WriteRune(char)
+		width += size
+	}
+	builder.WriteString("\r\n")
+	return builder.String()
+}
+
+// formatICalAmount returns a flex amount in short signed form, e.g. "+1h30m", "-45m" or "+0m"
+func formatICalAmount(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Minute)
+	hours := d / time.Hour
+	minutes := (d - hours*time.Hour) / time.Minute
+	text := sign
+	if hours > 0 {
+		text += fmt.Sprintf("%dh", hours)
+	}
+	if minutes > 0 || hours == 0 {
+		text += fmt.Sprintf("%dm", minutes)
+	}
+	return text
+}
+
+// WriteICalendar writes an iCalendar feed with an all-day VEVENT for each entry of the given customers
+// within from and to, inclusive, where nil means from the first or to the last entry of each customer.
+// The summary tells the amount and customer, like "+1h30m flex – acme", and the description
+// has the comment, if any, and the running balance of the customer at the end of the date.
+// Events get UIDs from the entry IDs, so calendars subscribing to the feed can follow changes.
+// The given time is used as DTSTAMP. Amounts are formatted by formatAmount, or in short signed form,
+// like "+1h30m", if it's nil.
+func WriteICalendar(
+	writer io.Writer,
+	customers Customers,
+	from, to *time.Time,
+	now time.Time,
+	formatAmount func(time.Duration) string,
+) error {
+	if formatAmount == nil {
+		formatAmount = formatICalAmount
+	}
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//oddlid//flextime//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:flextime",
+	}
+	stamp := now.UTC().Format(iCalDateTimeFormat) + "Z"
+	for _, customer := range customers {
+		first, err := customer.Entries.FirstDate()
+		if err != nil {
+			continue
+		}
+		last, _ := customer.Entries.LastDate()
+		if from != nil {
+			first = from
+		}
+		if to != nil {
+			last = to
+		}
+		entries := customer.Entries.FilterByDateRange(*first, *last)
+		entries.Sort(EntrySortByDateAscending)
+		history := customer.BalanceHistory(nil, nil)
+
+		for _, entry := range entries {
+			date := DateOf(entry.Date)
+			description := fmt.Sprintf("Balance: %s", formatAmount(history.At(date)))
+			if entry.Comment != "" {
+				description = entry.Comment + "\n" + description
+			}
+			lines = append(
+				lines,
+				"BEGIN:VEVENT",
+				fmt.Sprintf("UID:%s-%s@flextime", entry.ID, escapeICalText(customer.Name)),
+				"DTSTAMP:"+stamp,
+				"DTSTART;VALUE=DATE:"+date.Format(iCalDateFormat),
+				"DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format(iCalDateFormat),
+				"SUMMARY:"+escapeICalText(fmt.Sprintf("%s flex – %s", formatAmount(entry.Amount), customer.Title())),
+				"DESCRIPTION:"+escapeICalText(description),
+				"CATEGORIES:"+escapeICalText(customer.Name),
+				"TRANSP:TRANSPARENT",
+				"END:VEVENT",
+			)
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(writer, foldICalLine(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package flex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseICalendar(t *testing.T) {
+	input := strings.Join(
+		[]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:20211224",
+			"DTEND;VALUE=DATE:20211227",
+			"SUMMARY:Christmas",
+			"  holidays",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART:20211206T090000Z",
+			`SUMMARY:Finland\, independence day`,
+			"END:VEVENT",
+			"END:VCALENDAR",
+		},
+		"\r\n",
+	)
+	calendar, err := ParseICalendar(strings.NewReader(input), DayHoliday, time.UTC)
+	assert.NoError(t, err)
+	if assert.Equal(t, 4, calendar.Len()) {
+		assert.True(t, time.Date(2021, time.December, 6, 0, 0, 0, 0, time.UTC).Equal(calendar[0].Date))
+		assert.Equal(t, "Finland, independence day", calendar[0].Name)
+		assert.True(t, time.Date(2021, time.December, 26, 0, 0, 0, 0, time.UTC).Equal(calendar[3].Date))
+		assert.Equal(t, "Christmas holidays", calendar[3].Name)
+		assert.Equal(t, DayHoliday, calendar[3].Kind)
+	}
+}
+
+func TestParseICalendarTimes(t *testing.T) {
+	location := time.FixedZone("CET", 3600)
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		dates []time.Time
+	}{
+		{
+			name:  "utcInLocation",
+			start: "DTSTART:20220103T233000Z",
+			end:   "DTEND:20220104T010000Z",
+			dates: []time.Time{ymd(2022, 1, 4)},
+		},
+		{
+			name:  "tzid",
+			start: "DTSTART;TZID=America/New_York:20220103T200000",
+			end:   `DTEND;TZID="America/New_York":20220103T210000`,
+			dates: []time.Time{ymd(2022, 1, 4)},
+		},
+		{
+			name:  "floatingEndAtMidnight",
+			start: "DTSTART:20220103T233000",
+			end:   "DTEND:20220104T000000",
+			dates: []time.Time{ymd(2022, 1, 3)},
+		},
+		{
+			name:  "timedEndIncluded",
+			start: "DTSTART:20220110T090000",
+			end:   "DTEND:20220112T120000",
+			dates: []time.Time{ymd(2022, 1, 10), ymd(2022, 1, 11), ymd(2022, 1, 12)},
+		},
+		{
+			name:  "dateEndExcluded",
+			start: "DTSTART;VALUE=DATE:20220120",
+			end:   "DTEND;VALUE=DATE:20220122",
+			dates: []time.Time{ymd(2022, 1, 20), ymd(2022, 1, 21)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := strings.Join([]string{"BEGIN:VEVENT", tt.start, tt.end, "SUMMARY:off", "END:VEVENT"}, "\n")
+			calendar, err := ParseICalendar(strings.NewReader(input), DayAbsence, location)
+			assert.NoError(t, err)
+			dates := make([]time.Time, 0, calendar.Len())
+			for _, day := range calendar {
+				dates = append(dates, day.Date)
+			}
+			assert.Equal(t, tt.dates, dates)
+		})
+	}
+}
+
+func TestParseICalendarInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "noStart",
+			input: "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n",
+		},
+		{
+			name:  "badDate",
+			input: "BEGIN:VEVENT\nDTSTART:2021-12-24\nEND:VEVENT\n",
+		},
+		{
+			name:  "unterminated",
+			input: "BEGIN:VEVENT\nDTSTART:20211224\n",
+		},
+		{
+			name:  "endWithoutBegin",
+			input: "END:VEVENT\n",
+		},
+		{
+			name:  "unknownZone",
+			input: "BEGIN:VEVENT\nDTSTART;TZID=Nowhere/Special:20211224T090000\nEND:VEVENT\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseICalendar(strings.NewReader(tt.input), DayHoliday, time.UTC)
+			assert.ErrorIs(t, err, ErrInvalidICalendar)
+		})
+	}
+}
+
+func TestWriteICalendar(t *testing.T) {
+	customers := Customers{
+		{
+			Name:        "acme",
+			DisplayName: "ACME Corp",
+			Entries: Entries{
+				{ID: "a2", Date: ymd(2022, 1, 4), Amount: -45 * time.Minute},
+				{ID: "a1", Date: ymd(2022, 1, 3), Amount: 90 * time.Minute, Comment: "release, finally"},
+				{ID: "a3", Date: ymd(2022, 1, 10), Amount: time.Hour},
+			},
+		},
+		{Name: "empty"},
+	}
+	from, to := ymd(2022, 1, 1), ymd(2022, 1, 5)
+	now := time.Date(2022, 1, 6, 12, 0, 0, 0, time.UTC)
+
+	builder := strings.Builder{}
+	assert.NoError(t, WriteICalendar(&builder, customers, &from, &to, now, nil))
+	output := builder.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	assert.Contains(t, output, "BEGIN:VEVENT\r\nUID:a1-acme@flextime\r\nDTSTAMP:20220106T120000Z\r\nDTSTART;VALUE=DATE:20220103\r\nDTEND;VALUE=DATE:20220104\r\n")
+	assert.Contains(t, output, "SUMMARY:+1h30m flex – ACME Corp\r\n")
+	assert.Contains(t, output, `DESCRIPTION:release\, finally\nBalance: +1h30m`+"\r\n")
+	assert.Contains(t, output, "SUMMARY:-45m flex – ACME Corp\r\n")
+	assert.Contains(t, output, `DESCRIPTION:Balance: +45m`+"\r\n")
+	assert.NotContains(t, output, "UID:a3")
+	assert.Equal(t, 2, strings.Count(output, "BEGIN:VEVENT"))
+
+	// What is written can be read back, as days in a calendar
+	calendar, err := ParseICalendar(strings.NewReader(output), DayHoliday, time.UTC)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, calendar.Len()) {
+		assert.Equal(t, ymd(2022, 1, 3), calendar[0].Date)
+		assert.Equal(t, "+1h30m flex – ACME Corp", calendar[0].Name)
+	}
+
+	// Amounts can be formatted otherwise
+	builder.Reset()
+	formatClock := func(d time.Duration) string { return DurationFormatClock.Format(d, 0) }
+	assert.NoError(t, WriteICalendar(&builder, customers, &from, &to, now, formatClock))
+	assert.Contains(t, builder.String(), "SUMMARY:+1:30 flex – ACME Corp\r\n")
+	assert.Contains(t, builder.String(), `DESCRIPTION:Balance: +0:45`+"\r\n")
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("æ", 40)
+	folded := foldICalLine(line)
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n ")
+	assert.Len(t, parts, 2)
+	assert.LessOrEqual(t, len(parts[0]), 75)
+	assert.Equal(t, line, strings.Join(parts, ""))
+}
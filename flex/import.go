@@ -0,0 +1,147 @@
+package flex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An ImportRow is an entry read from another format, with the line it was on
+type ImportRow struct {
+	Line     int
+	Customer string // empty if the input doesn't say
+	Entry    Entry
+}
+
+// A RejectedRow is a line that could not be read as an entry, and why
+type RejectedRow struct {
+	Line int
+	Err  error
+}
+
+// An ImportResult tells what ImportRows did for one customer
+type ImportResult struct {
+	Customer string
+	TransferResult
+}
+
+// ImportRows adds the entries of the rows to their customers, which are added if needed.
+// Rows without a customer go to defaultCustomer, or the default customer if that is empty.
+// Dates that a customer already has entries for are handled according to policy, like for TransferEntries,
+// where TransferFail returns ErrTransferConflict without changing anything, for any customer.
+// Results are in the order each customer was first seen in the rows.
+func (db *DB) ImportRows(rows []ImportRow, defaultCustomer string, policy TransferPolicy) ([]ImportResult, error) {
+	names := make([]string, 0)
+	entries := make(map[string]Entries)
+	for idx := range rows {
+		name := rows[idx].Customer
+		if name == "" && defaultCustomer == "" {
+			defaultCustomer = db.GetDefaultCustomer().Name
+		}
+		if name == "" {
+			name = defaultCustomer
+		}
+		if _, found := entries[name]; !found {
+			names = append(names, name)
+		}
+		entry := rows[idx].Entry
+		entries[name] = append(entries[name], &entry)
+	}
+
+	if policy == TransferFail {
+		for _, name := range names {
+			customer, err := db.GetCustomer(name)
+			if err != nil {
+				continue
+			}
+			if conflicts := ConflictingDates(customer, &Customer{Entries: entries[name]}); len(conflicts) > 0 {
+				dates := make([]string, 0, len(conflicts))
+				for _, date := range conflicts {
+					dates = append(dates, date.Format(ShortDateFormat))
+				}
+				return nil, fmt.Errorf("%w: %s already has entries for %s", ErrTransferConflict, customer.Name, strings.Join(dates, ", "))
+			}
+		}
+	}
+
+	results := make([]ImportResult, 0, len(names))
+	for _, name := range names {
+		source := &Customer{Name: "import", Entries: entries[name]}
+		target := db.getOrAddCustomer(name)
+		result, err := db.TransferEntries(source, target, source.Entries, policy, true)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, ImportResult{Customer: target.Name, TransferResult: result})
+	}
+	return results, nil
+}
+
+// A TimeInterval is a span of time worked, as tracked by tools like Timewarrior, or in timeclock files
+type TimeInterval struct {
+	Line       int
+	Start      time.Time
+	End        time.Time
+	Tags       []string
+	Annotation string
+}
+
+// IntervalRows sums the intervals up to one row of time worked per customer and date, in order of date.
+// customerOf returns the customer name for the tags of an interval, or an empty string for the default.
+// Each interval counts for the date it starts on, in the given location.
+// The comment is made from the annotations, or the tags not naming the customer for intervals without one.
+func IntervalRows(intervals []TimeInterval, location *time.Location, customerOf func(tags []string) string) []ImportRow {
+	type rowKey struct {
+		customer string
+		date     time.Time
+	}
+	keys := make([]rowKey, 0)
+	rows := make(map[rowKey]*ImportRow)
+	comments := make(map[rowKey][]string)
+	for _, interval := range intervals {
+		customer := customerOf(interval.Tags)
+		key := rowKey{customer: customer, date: DateOf(interval.Start.In(location))}
+		row, found := rows[key]
+		if !found {
+			row = &ImportRow{Line: interval.Line, Customer: customer, Entry: Entry{Date: key.date}}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+		row.Entry.Worked += interval.End.Sub(interval.Start)
+
+		comment := interval.Annotation
+		if comment == "" {
+			tags := make([]string, 0, len(interval.Tags))
+			for _, tag := range interval.Tags {
+				if tag != customer {
+					tags = append(tags, tag)
+				}
+			}
+			comment = strings.Join(tags, ", ")
+		}
+		if comment != "" && !containsString(comments[key], comment) {
+			comments[key] = append(comments[key], comment)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].date.Before(keys[j].date)
+	})
+	result := make([]ImportRow, 0, len(keys))
+	for _, key := range keys {
+		row := rows[key]
+		row.Entry.Comment = strings.Join(comments[key], "; ")
+		result = append(result, *row)
+	}
+	return result
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportRows(t *testing.T) {
+	rows := []ImportRow{
+		{Line: 2, Customer: "right", Entry: Entry{Date: ymd(2022, 1, 3), Worked: 8 * time.Hour}},
+		{Line: 3, Customer: "right", Entry: Entry{Date: ymd(2022, 1, 4), Amount: time.Hour}},
+		{Line: 4, Entry: Entry{Date: ymd(2022, 1, 5), Amount: 30 * time.Minute}},
+	}
+
+	db := transferTestDB()
+	_, err := db.ImportRows(rows, "new", TransferFail)
+	assert.ErrorIs(t, err, ErrTransferConflict)
+	assert.Equal(t, transferTestDB(), db)
+
+	results, err := db.ImportRows(rows, "new", TransferSkip)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "right", results[0].Customer)
+		assert.Equal(t, 1, results[0].Transferred.Len())
+		assert.Equal(t, 1, results[0].Skipped.Len())
+		assert.Equal(t, "new", results[1].Customer)
+	}
+	right, err := db.GetCustomer("right")
+	assert.NoError(t, err)
+	// the worked entry is 8h - 6h = 2h for right
+	assert.Equal(t, time.Hour, right.GetTotalFlex())
+	customer, err := db.GetCustomer("new")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, customer.GetTotalFlex())
+}
+
+func TestIntervalRows(t *testing.T) {
+	location := time.FixedZone("CET", 3600)
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	intervals := []TimeInterval{
+		{Line: 1, Start: at(4, 8), End: at(4, 12), Tags: []string{"acme", "support"}},
+		{Line: 2, Start: at(3, 23), End: at(4, 1), Tags: []string{"other"}, Annotation: "late"}, // the 4th in CET
+		{Line: 3, Start: at(4, 13), End: at(4, 15), Tags: []string{"acme", "support"}},
+	}
+	customerOf := func(tags []string) string {
+		if len(tags) > 0 && tags[0] == "acme" {
+			return "acme"
+		}
+		return ""
+	}
+	assert.Equal(
+		t,
+		[]ImportRow{
+			{Line: 1, Customer: "acme", Entry: Entry{Date: ymd(2022, 1, 4), Worked: 6 * time.Hour, Comment: "support"}},
+			{Line: 2, Entry: Entry{Date: ymd(2022, 1, 4), Worked: 2 * time.Hour, Comment: "late"}},
+		},
+		IntervalRows(intervals, location, customerOf),
+	)
+}
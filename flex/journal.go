@@ -0,0 +1,363 @@
+package flex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// JournalKind tells what a JournalRecord did
+type JournalKind string
+
+const (
+	JournalChange JournalKind = "change" // a command modified the DB
+	JournalUndo   JournalKind = "undo"   // a previous change was reverted
+	JournalRedo   JournalKind = "redo"   // a previously reverted change was applied again
+)
+
+// A CustomerChange holds the state of a single customer before and after a change.
+// A missing Before means the customer was added, a missing After that it was removed.
+// If Customer is empty, the change is to the DB wide settings, i.e. the default schedule and the calendar.
+type CustomerChange struct {
+	Customer string          `json:"customer,omitempty"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+}
+
+// A JournalRecord is one line in the journal
+type JournalRecord struct {
+	Seq     int              `json:"seq"`
+	Time    time.Time        `json:"time"`
+	Kind    JournalKind      `json:"kind"`
+	Command string           `json:"command"`
+	Ref     int              `json:"ref,omitempty"` // Seq of the record undone or redone
+	Changes []CustomerChange `json:"changes"`
+}
+
+// A Journal is an append-only log of the changes made to a DB file,
+// used to undo and redo them.
+type Journal struct {
+	FileName string
+	Records  []*JournalRecord
+}
+
+// settings is what is journaled for the DB itself, apart from the customers
+type settings struct {
+	DefaultSchedule *Schedule `json:"default_schedule,omitempty"`
+	Calendar        Calendar  `json:"calendar,omitempty"`
+}
+
+// JournalFileName returns the name of the journal kept for the given DB file
+func JournalFileName(fileName string) string {
+	return fileName + ".journal"
+}
+
+// OpenJournal reads all records from the journal for the given DB file.
+// A journal that does not exist yet is not an error, but gives an empty Journal.
+func OpenJournal(fileName string) (*Journal, error) {
+	journal := &Journal{FileName: JournalFileName(fileName)}
+	file, err := os.Open(journal.FileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return journal, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		record := &JournalRecord{}
+		if err = json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: %v", ErrInvalidJournal, journal.FileName, line, err)
+		}
+		journal.Records = append(journal.Records, record)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// Append writes a new record with the next sequence number to the end of the journal, and returns it
+func (journal *Journal) Append(kind JournalKind, command string, ref int, changes []CustomerChange) (*JournalRecord, error) {
+	record := &JournalRecord{
+		Seq:     1,
+		Time:    time.Now(),
+		Kind:    kind,
+		Command: command,
+		Ref:     ref,
+		Changes: changes,
+	}
+	if len(journal.Records) > 0 {
+		record.Seq = journal.Records[len(journal.Records)-1].Seq + 1
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(journal.FileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	journal.Records = append(journal.Records, record)
+	return record, nil
+}
+
+// Get returns the record with the given sequence number, or nil if not found
+func (journal *Journal) Get(seq int) *JournalRecord {
+	for _, record := range journal.Records {
+		if record.Seq == seq {
+			return record
+		}
+	}
+	return nil
+}
+
+// stacks replays the journal and returns the changes that can be undone and redone,
+// with the next one to undo or redo last.
+// A new change after an undo discards what could be redone, like in an editor.
+func (journal *Journal) stacks() ([]*JournalRecord, []*JournalRecord) {
+	undo := make([]*JournalRecord, 0)
+	redo := make([]*JournalRecord, 0)
+	for _, record := range journal.Records {
+		switch record.Kind {
+		case JournalChange:
+			undo = append(undo, record)
+			redo = redo[:0]
+		case JournalUndo:
+			if len(undo) > 0 {
+				redo = append(redo, undo[len(undo)-1])
+				undo = undo[:len(undo)-1]
+			}
+		case JournalRedo:
+			if len(redo) > 0 {
+				undo = append(undo, redo[len(redo)-1])
+				redo = redo[:len(redo)-1]
+			}
+		}
+	}
+	return undo, redo
+}
+
+// Undoable returns the change the next undo would revert, or nil if there is nothing to undo
+func (journal *Journal) Undoable() *JournalRecord {
+	undo, _ := journal.stacks()
+	if len(undo) == 0 {
+		return nil
+	}
+	return undo[len(undo)-1]
+}
+
+// Redoable returns the change the next redo would apply again, or nil if there is nothing to redo
+func (journal *Journal) Redoable() *JournalRecord {
+	_, redo := journal.stacks()
+	if len(redo) == 0 {
+		return nil
+	}
+	return redo[len(redo)-1]
+}
+
+// Undo reverts the next undoable change in the given DB, and returns the record of that change.
+// The caller saves the DB and then appends a JournalUndo record referring to it.
+// If the DB was changed without being journaled since, ErrJournalConflict is returned, unless force is true.
+func (journal *Journal) Undo(db *DB, force bool) (*JournalRecord, error) {
+	record := journal.Undoable()
+	if record == nil {
+		return nil, ErrNothingToUndo
+	}
+	changes := InvertChanges(record.Changes)
+	if err := ApplyChanges(db, changes, force); err != nil {
+		return nil, fmt.Errorf("undo #%d: %w", record.Seq, err)
+	}
+	return record, nil
+}
+
+// Redo applies the last undone change again in the given DB.
+// See Undo for how conflicts are handled.
+func (journal *Journal) Redo(db *DB, force bool) (*JournalRecord, error) {
+	record := journal.Redoable()
+	if record == nil {
+		return nil, ErrNothingToRedo
+	}
+	if err := ApplyChanges(db, record.Changes, force); err != nil {
+		return nil, fmt.Errorf("redo #%d: %w", record.Seq, err)
+	}
+	return record, nil
+}
+
+func marshalCustomer(customer *Customer) (json.RawMessage, error) {
+	if customer == nil {
+		return nil, nil
+	}
+	return json.Marshal(customer)
+}
+
+func marshalSettings(db *DB) (json.RawMessage, error) {
+	return json.Marshal(settings{DefaultSchedule: db.DefaultSchedule, Calendar: db.Calendar})
+}
+
+// DiffDB compares two states of a DB, and returns a change for each customer that differs,
+// and one for the DB settings if they differ. The order of customers is not considered.
+func DiffDB(before, after *DB) ([]CustomerChange, error) {
+	changes := make([]CustomerChange, 0)
+
+	beforeSettings, err := marshalSettings(before)
+	if err != nil {
+		return nil, err
+	}
+	afterSettings, err := marshalSettings(after)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(beforeSettings, afterSettings) {
+		changes = append(changes, CustomerChange{Before: beforeSettings, After: afterSettings})
+	}
+
+	diffCustomer := func(name string, beforeCustomer, afterCustomer *Customer) error {
+		beforeJSON, err := marshalCustomer(beforeCustomer)
+		if err != nil {
+			return err
+		}
+		afterJSON, err := marshalCustomer(afterCustomer)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(beforeJSON, afterJSON) {
+			changes = append(changes, CustomerChange{Customer: name, Before: beforeJSON, After: afterJSON})
+		}
+		return nil
+	}
+
+	for _, customer := range after.Customers {
+		if err = diffCustomer(customer.Name, before.findCustomerExact(customer.Name), customer); err != nil {
+			return nil, err
+		}
+	}
+	for _, customer := range before.Customers {
+		if after.findCustomerExact(customer.Name) == nil {
+			if err = diffCustomer(customer.Name, customer, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// InvertChanges returns the changes that revert the given ones
+func InvertChanges(changes []CustomerChange) []CustomerChange {
+	inverted := make([]CustomerChange, 0, len(changes))
+	for idx := len(changes) - 1; idx >= 0; idx-- {
+		change := changes[idx]
+		inverted = append(inverted, CustomerChange{
+			Customer: change.Customer,
+			Before:   change.After,
+			After:    change.Before,
+		})
+	}
+	return inverted
+}
+
+// ApplyChanges sets each customer in the given changes to its After state, adding or removing
+// the customer as needed. Unless force is true, the current state in the DB must match the Before state
+// of every change, or ErrJournalConflict is returned and the DB is left unchanged.
+func ApplyChanges(db *DB, changes []CustomerChange, force bool) error {
+	if !force {
+		for _, change := range changes {
+			var current json.RawMessage
+			var err error
+			if change.Customer == "" {
+				current, err = marshalSettings(db)
+			} else {
+				current, err = marshalCustomer(db.findCustomerExact(change.Customer))
+			}
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(current, change.Before) {
+				if change.Customer == "" {
+					return fmt.Errorf("%w: default schedule or calendar", ErrJournalConflict)
+				}
+				return fmt.Errorf("%w: customer %q", ErrJournalConflict, change.Customer)
+			}
+		}
+	}
+
+	for _, change := range changes {
+		if change.Customer == "" {
+			state := settings{}
+			if err := json.Unmarshal(change.After, &state); err != nil {
+				return err
+			}
+			db.DefaultSchedule = state.DefaultSchedule
+			db.Calendar = state.Calendar
+			continue
+		}
+
+		existing := db.findCustomerExact(change.Customer)
+		if change.After == nil {
+			if existing != nil {
+				db.removeCustomer(existing)
+			}
+			continue
+		}
+		customer := &Customer{}
+		if err := json.Unmarshal(change.After, customer); err != nil {
+			return err
+		}
+		if existing != nil {
+			*existing = *customer
+		} else {
+			db.Customers = append(db.Customers, customer)
+		}
+	}
+
+	return nil
+}
+
+// findCustomerExact returns the customer with exactly the given name, or nil.
+// Unlike GetCustomer, the case must match, so that a rename that only changes case is journaled as such.
+func (db *DB) findCustomerExact(name string) *Customer {
+	for _, customer := range db.Customers {
+		if customer.Name == name {
+			return customer
+		}
+	}
+	return nil
+}
+
+// removeCustomer removes exactly the given customer, preserving the order of the others.
+// Unlike Customers.Delete, it does not remove another customer whose name only differs in case.
+func (db *DB) removeCustomer(customer *Customer) {
+	for idx, candidate := range db.Customers {
+		if candidate == customer {
+			db.Customers = append(db.Customers[:idx], db.Customers[idx+1:]...)
+			return
+		}
+	}
+}
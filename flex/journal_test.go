@@ -0,0 +1,139 @@
+package flex
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func journalTestDB() *DB {
+	db := NewDB()
+	db.SetEntryForCustomer("Customer1", Entry{ID: "00000001", Date: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), Amount: time.Hour}, false)
+	db.SetEntryForCustomer("Customer2", Entry{ID: "00000002", Date: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC), Amount: -time.Hour}, false)
+	return db
+}
+
+func TestDiffDB(t *testing.T) {
+	before := journalTestDB()
+	after := journalTestDB()
+
+	changes, err := DiffDB(before, after)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	after.SetEntryForCustomer("Customer1", Entry{ID: "00000003", Date: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC), Amount: time.Minute}, false)
+	after.Customers.Delete(Customer{Name: "Customer2"})
+	after.AddCustomer("Customer3")
+	after.DefaultSchedule = StandardSchedule(DefaultWorkdayLength)
+
+	changes, err = DiffDB(before, after)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 4) {
+		assert.Equal(t, "", changes[0].Customer)
+		assert.Equal(t, "Customer1", changes[1].Customer)
+		assert.Equal(t, "Customer3", changes[2].Customer)
+		assert.Nil(t, changes[2].Before)
+		assert.Equal(t, "Customer2", changes[3].Customer)
+		assert.Nil(t, changes[3].After)
+	}
+
+	// Reverting the changes on the new state gives back the old
+	assert.NoError(t, ApplyChanges(after, InvertChanges(changes), false))
+	changes, err = DiffDB(before, after)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestApplyChangesNameCase(t *testing.T) {
+	db := NewDB()
+	db.AddCustomer("acme")
+	before, err := db.Clone()
+	assert.NoError(t, err)
+	db.Customers = append(db.Customers, &Customer{Name: "ACME"})
+	changes, err := DiffDB(before, db)
+	assert.NoError(t, err)
+
+	// Undoing the creation of ACME leaves acme alone
+	assert.NoError(t, ApplyChanges(db, InvertChanges(changes), false))
+	if assert.Equal(t, 1, db.Customers.Len()) {
+		assert.Equal(t, "acme", db.Customers[0].Name)
+	}
+}
+
+func TestApplyChangesConflict(t *testing.T) {
+	before := journalTestDB()
+	after := journalTestDB()
+	after.SetEntryForCustomer("Customer1", Entry{Date: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC), Amount: time.Minute}, false)
+	changes, err := DiffDB(before, after)
+	assert.NoError(t, err)
+
+	// Applying the change again, where it was already applied, must fail without force
+	err = ApplyChanges(after, changes, false)
+	assert.ErrorIs(t, err, ErrJournalConflict)
+
+	assert.NoError(t, ApplyChanges(after, changes, true))
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "flextime.json")
+	journal, err := OpenJournal(fileName)
+	assert.NoError(t, err)
+	assert.Nil(t, journal.Undoable())
+	assert.Nil(t, journal.Redoable())
+
+	db := journalTestDB()
+	record := func(command string, change func()) {
+		before, err := db.Clone()
+		assert.NoError(t, err)
+		change()
+		changes, err := DiffDB(before, db)
+		assert.NoError(t, err)
+		_, err = journal.Append(JournalChange, command, 0, changes)
+		assert.NoError(t, err)
+	}
+	record("add 1", func() {
+		db.AddEntryForCustomer("Customer1", Entry{Date: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC), Amount: time.Minute})
+	})
+	record("add 2", func() {
+		db.AddEntryForCustomer("Customer1", Entry{Date: time.Date(2022, 1, 6, 0, 0, 0, 0, time.UTC), Amount: time.Minute})
+	})
+
+	undone, err := journal.Undo(db, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "add 2", undone.Command)
+	_, err = journal.Append(JournalUndo, "undo", undone.Seq, nil)
+	assert.NoError(t, err)
+	customer, _ := db.GetCustomer("Customer1")
+	assert.Len(t, customer.Entries, 2)
+
+	// The journal is read back the same from file
+	journal, err = OpenJournal(fileName)
+	assert.NoError(t, err)
+	assert.Len(t, journal.Records, 3)
+	assert.Equal(t, "add 1", journal.Undoable().Command)
+	assert.Equal(t, "add 2", journal.Redoable().Command)
+
+	redone, err := journal.Redo(db, false)
+	assert.NoError(t, err)
+	assert.Equal(t, undone.Seq, redone.Seq)
+	_, err = journal.Append(JournalRedo, "redo", redone.Seq, nil)
+	assert.NoError(t, err)
+	customer, _ = db.GetCustomer("Customer1")
+	assert.Len(t, customer.Entries, 3)
+	assert.Nil(t, journal.Redoable())
+
+	// A new change after an undo leaves nothing to redo
+	_, err = journal.Undo(db, false)
+	assert.NoError(t, err)
+	_, err = journal.Append(JournalUndo, "undo", 2, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, journal.Redoable())
+	record("add 3", func() {
+		db.AddEntryForCustomer("Customer2", Entry{Date: time.Date(2022, 1, 7, 0, 0, 0, 0, time.UTC), Amount: time.Minute})
+	})
+	assert.Nil(t, journal.Redoable())
+	_, err = journal.Redo(db, false)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+}
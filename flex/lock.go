@@ -0,0 +1,85 @@
+package flex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockRetryInterval is how often AcquireLock retries while the lock is held elsewhere
+const lockRetryInterval = 100 * time.Millisecond
+
+// A Lock is an exclusive advisory lock for a DB file, held through a separate lock file,
+// since the DB file itself is replaced on every save.
+type Lock struct {
+	file *os.File
+}
+
+// LockFileName returns the name of the lock file used for the given DB file
+func LockFileName(fileName string) string {
+	return fileName + ".lock"
+}
+
+// AcquireLock takes an exclusive lock for the given DB file, retrying until the timeout
+// has passed if another process holds it. Returns ErrLocked if the lock could not be taken in time.
+// The lock must be released with Release when done.
+func AcquireLock(fileName string, timeout time.Duration) (*Lock, error) {
+	lockFileName := LockFileName(fileName)
+	file, err := os.OpenFile(lockFileName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = tryLockFile(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errWouldBlock) {
+			file.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			owner := readLockOwner(file)
+			file.Close()
+			return nil, fmt.Errorf("%w: %s held by pid %s (waited %v)", ErrLocked, lockFileName, owner, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	// The pid is only informative, for the error message of anyone waiting
+	if err = file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock. The lock file is left in place, as removing it could
+// let two processes lock different files.
+func (lock *Lock) Release() error {
+	if lock == nil || lock.file == nil {
+		return nil
+	}
+	lock.file.Truncate(0)
+	err := unlockFile(lock.file)
+	if closeErr := lock.file.Close(); err == nil {
+		err = closeErr
+	}
+	lock.file = nil
+	return err
+}
+
+func readLockOwner(file *os.File) string {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	owner := strings.TrimSpace(string(buf[:n]))
+	if owner == "" {
+		return "unknown"
+	}
+	return owner
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package flex
+
+import (
+	"errors"
+	"os"
+)
+
+// Advisory locking is only implemented with flock on unix like systems.
+// Elsewhere, locking always succeeds.
+
+var errWouldBlock = errors.New("lock would block")
+
+func tryLockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package flex
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireLock(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "flextime.json")
+
+	lock, err := AcquireLock(fileName, time.Second)
+	assert.NoError(t, err)
+	assert.FileExists(t, LockFileName(fileName))
+
+	_, err = AcquireLock(fileName, 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	assert.NoError(t, lock.Release())
+	assert.NoError(t, lock.Release())
+
+	lock, err = AcquireLock(fileName, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Release())
+}
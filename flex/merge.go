@@ -0,0 +1,184 @@
+package flex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergePolicy decides what MergeCustomers does with dates that have entries for both customers
+type MergePolicy uint8
+
+const (
+	MergeFail      MergePolicy = iota // refuse to merge
+	MergeSum                          // replace the entries for the date with one holding their sum
+	MergeKeepLeft                     // keep the entries of the customer merged into
+	MergeKeepRight                    // keep the entries of the customer merged from
+)
+
+var mergePolicyNames = map[MergePolicy]string{
+	MergeFail:      "fail",
+	MergeSum:       "sum",
+	MergeKeepLeft:  "keep-left",
+	MergeKeepRight: "keep-right",
+}
+
+// ParseMergePolicy returns the MergePolicy matching the given name
+func ParseMergePolicy(name string) (MergePolicy, error) {
+	for policy, policyName := range mergePolicyNames {
+		if strings.EqualFold(name, policyName) {
+			return policy, nil
+		}
+	}
+	return MergeFail, fmt.Errorf("%w: %q", ErrInvalidMergePolicy, name)
+}
+
+// MergePolicyOptions returns the valid names for MergePolicy, for use in help texts
+func MergePolicyOptions() string {
+	return strings.Join(
+		[]string{
+			MergeFail.String(),
+			MergeSum.String(),
+			MergeKeepLeft.String(),
+			MergeKeepRight.String(),
+		},
+		", ",
+	)
+}
+
+func (policy MergePolicy) String() string {
+	if name, ok := mergePolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("MergePolicy(%d)", policy)
+}
+
+// RenameCustomer gives the customer with oldName the name newName.
+// Fails if there is no customer with oldName, or if another customer already has newName.
+// Changing only the case of a name is fine.
+func (db *DB) RenameCustomer(oldName, newName string) (*Customer, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrInvalidCustomerName)
+	}
+	customer, err := db.GetCustomer(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := db.GetCustomer(newName); err == nil && existing != customer {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerExists, existing.Name)
+	}
+	customer.Name = newName
+	return customer, nil
+}
+
+// ConflictingDates returns the dates, in order, that have entries for both customers
+func ConflictingDates(left, right *Customer) []time.Time {
+	leftDates := make(map[time.Time]bool)
+	for _, entry := range left.Entries {
+		leftDates[DateOf(entry.Date)] = true
+	}
+	seen := make(map[time.Time]bool)
+	conflicts := make([]time.Time, 0)
+	for _, entry := range right.Entries {
+		date := DateOf(entry.Date)
+		if leftDates[date] && !seen[date] {
+			conflicts = append(conflicts, date)
+			seen[date] = true
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Before(conflicts[j])
+	})
+	return conflicts
+}
+
+// MergeCustomers moves the entries of the customer named rightName to the customer named leftName,
+// and then removes the right customer. Dates with entries for both are handled according to policy,
+// where MergeFail returns ErrMergeConflict without changing anything.
+// Metadata the left customer lacks, like a schedule or notes, is taken from the right.
+// A running session is moved too, but if both customers have one, nothing is changed.
+func (db *DB) MergeCustomers(leftName, rightName string, policy MergePolicy) (*Customer, error) {
+	left, err := db.GetCustomer(leftName)
+	if err != nil {
+		return nil, err
+	}
+	right, err := db.GetCustomer(rightName)
+	if err != nil {
+		return nil, err
+	}
+	if left == right {
+		return nil, fmt.Errorf("%w: can't merge %s with itself", ErrInvalidCustomerName, left.Name)
+	}
+	if left.HasSession() && right.HasSession() {
+		return nil, fmt.Errorf("%w: both %s and %s", ErrSessionRunning, left.Name, right.Name)
+	}
+
+	conflicts := ConflictingDates(left, right)
+	if len(conflicts) > 0 && policy == MergeFail {
+		dates := make([]string, 0, len(conflicts))
+		for _, date := range conflicts {
+			dates = append(dates, date.Format(ShortDateFormat))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrMergeConflict, strings.Join(dates, ", "))
+	}
+
+	for _, date := range conflicts {
+		switch policy {
+		case MergeSum:
+			sum := Entry{Date: date}
+			comments := make([]string, 0)
+			for _, entry := range append(left.Entries.FilterByDate(date), right.Entries.FilterByDate(date)...) {
+				if sum.ID == "" {
+					sum.ID = entry.ID
+				}
+				sum.Amount += entry.Amount
+				sum.Worked += entry.Worked
+				if entry.Comment != "" {
+					comments = append(comments, entry.Comment)
+				}
+			}
+			sum.Comment = strings.Join(comments, "; ")
+			left.Entries.DeleteByDate(date)
+			right.Entries.DeleteByDate(date)
+			left.AddEntry(sum)
+		case MergeKeepLeft:
+			right.Entries.DeleteByDate(date)
+		case MergeKeepRight:
+			left.Entries.DeleteByDate(date)
+		}
+	}
+	mergeInto(left, right)
+
+	db.Customers.Delete(*right)
+	return left, nil
+}
+
+// mergeInto adds all entries of right to left, and fills in metadata that left lacks from right.
+// Dates with entries for both, and sessions, must be dealt with before.
+func mergeInto(left, right *Customer) {
+	for _, entry := range right.Entries {
+		left.AddEntry(*entry)
+	}
+
+	if left.Session == nil {
+		left.Session = right.Session
+	}
+	if left.Schedule == nil {
+		left.Schedule = right.Schedule
+	}
+	if left.DisplayName == "" {
+		left.DisplayName = right.DisplayName
+	}
+	switch {
+	case left.Notes == "":
+		left.Notes = right.Notes
+	case right.Notes != "":
+		left.Notes += "\n" + right.Notes
+	}
+	if right.StartDate != nil && (left.StartDate == nil || right.StartDate.Before(*left.StartDate)) {
+		left.StartDate = right.StartDate
+	}
+	left.Archived = left.Archived && right.Archived
+}
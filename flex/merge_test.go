@@ -0,0 +1,121 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMergePolicy(t *testing.T) {
+	policy, err := ParseMergePolicy("Keep-Right")
+	assert.NoError(t, err)
+	assert.Equal(t, MergeKeepRight, policy)
+
+	_, err = ParseMergePolicy("keep-both")
+	assert.ErrorIs(t, err, ErrInvalidMergePolicy)
+}
+
+func TestRenameCustomer(t *testing.T) {
+	db := &DB{Customers: Customers{{Name: "acme"}, {Name: "other"}}}
+
+	customer, err := db.RenameCustomer("ACME", "ACME AB")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ACME AB", customer.Name)
+		assert.Same(t, db.Customers[0], customer)
+	}
+
+	customer, err = db.RenameCustomer("acme ab", "Acme AB")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Acme AB", customer.Name)
+	}
+
+	_, err = db.RenameCustomer("Acme AB", "Other")
+	assert.ErrorIs(t, err, ErrCustomerExists)
+	_, err = db.RenameCustomer("nobody", "somebody")
+	assert.ErrorIs(t, err, ErrNoSuchCustomer)
+	_, err = db.RenameCustomer("other", " ")
+	assert.ErrorIs(t, err, ErrInvalidCustomerName)
+}
+
+// mergeTestDB returns a DB where acme and typo both have entries on the 2nd
+func mergeTestDB() *DB {
+	return &DB{
+		Customers: Customers{
+			{
+				Name: "acme",
+				Entries: Entries{
+					{ID: "a1", Date: ymd(2022, 1, 1), Amount: time.Hour},
+					{ID: "a2", Date: ymd(2022, 1, 2), Amount: 2 * time.Hour, Comment: "left"},
+				},
+			},
+			{
+				Name:  "typo",
+				Notes: "from the typo",
+				Entries: Entries{
+					{ID: "b1", Date: ymd(2022, 1, 2), Amount: 30 * time.Minute, Comment: "right"},
+					{ID: "a1", Date: ymd(2022, 1, 3), Amount: -time.Hour},
+				},
+				Schedule: StandardSchedule(6 * time.Hour),
+			},
+		},
+	}
+}
+
+func TestMergeCustomers(t *testing.T) {
+	tests := []struct {
+		policy  MergePolicy
+		total   time.Duration
+		count   int
+		comment string // of the entry on the 2nd
+	}{
+		{MergeSum, 2*time.Hour + 30*time.Minute, 3, "left; right"},
+		{MergeKeepLeft, 2 * time.Hour, 3, "left"},
+		{MergeKeepRight, 30 * time.Minute, 3, "right"},
+	}
+	for _, tt := range tests {
+		db := mergeTestDB()
+		assert.Equal(t, []time.Time{ymd(2022, 1, 2)}, ConflictingDates(db.Customers[0], db.Customers[1]))
+
+		customer, err := db.MergeCustomers("acme", "typo", tt.policy)
+		if !assert.NoError(t, err, tt.policy) {
+			continue
+		}
+		assert.Equal(t, 1, db.Customers.Len(), tt.policy)
+		assert.Equal(t, "acme", customer.Name)
+		assert.Equal(t, tt.total, customer.GetTotalFlex(), tt.policy)
+		assert.Equal(t, tt.count, customer.Entries.Len(), tt.policy)
+		entries, err := customer.GetEntries(ymd(2022, 1, 2))
+		if assert.NoError(t, err) && assert.Equal(t, 1, entries.Len(), tt.policy) {
+			assert.Equal(t, tt.comment, entries[0].Comment, tt.policy)
+		}
+		assert.Equal(t, "from the typo", customer.Notes)
+		assert.Equal(t, StandardSchedule(6*time.Hour), customer.Schedule)
+
+		// IDs stay unique when moved
+		ids := make(map[string]bool)
+		for _, entry := range customer.Entries {
+			assert.False(t, ids[entry.ID], entry.ID)
+			ids[entry.ID] = true
+		}
+	}
+}
+
+func TestMergeCustomersFail(t *testing.T) {
+	db := mergeTestDB()
+	_, err := db.MergeCustomers("acme", "typo", MergeFail)
+	if assert.ErrorIs(t, err, ErrMergeConflict) {
+		assert.Contains(t, err.Error(), "2022-01-02")
+	}
+	assert.Equal(t, mergeTestDB(), db)
+
+	_, err = db.MergeCustomers("acme", "ACME", MergeSum)
+	assert.ErrorIs(t, err, ErrInvalidCustomerName)
+	_, err = db.MergeCustomers("acme", "nobody", MergeSum)
+	assert.ErrorIs(t, err, ErrNoSuchCustomer)
+
+	db.Customers[0].Session = &Session{Start: time.Now()}
+	db.Customers[1].Session = &Session{Start: time.Now()}
+	_, err = db.MergeCustomers("acme", "typo", MergeSum)
+	assert.ErrorIs(t, err, ErrSessionRunning)
+}
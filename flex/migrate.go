@@ -0,0 +1,181 @@
+package flex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SchemaVersion is the version of the JSON layout written by EncodeDB.
+// Files from before versioning have no schema_version, and are version 0.
+const SchemaVersion = 1
+
+// A Migration upgrades a JSON document of the DB from one schema version to the next
+type Migration struct {
+	From        int    // the version upgraded from, to From+1
+	Description string // what the migration does, in general
+	// Migrate changes the document in place, and returns a description of each change made
+	Migrate func(document map[string]any) ([]string, error)
+}
+
+// migrations holds the migration from each version before SchemaVersion, in order.
+// Changing the layout of the DB means adding one here, and increasing SchemaVersion.
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "give every entry an ID",
+		Migrate:     migrateEntryIDs,
+	},
+}
+
+// Migrations returns the registered migrations, in order
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// A MigrationReport tells what MigrateDocument did, or would do
+type MigrationReport struct {
+	From    int // the version of the document as read
+	To      int // the version after migrating
+	Changes []string
+}
+
+// NeedsMigration returns true if the document was at an older version
+func (report MigrationReport) NeedsMigration() bool {
+	return report.From < report.To
+}
+
+// schemaVersionOf returns the schema version of the document.
+// Documents from a newer version are refused with ErrInvalidSchemaVersion,
+// as decoding them could silently lose what this version doesn't know about.
+func schemaVersionOf(document map[string]any) (int, error) {
+	value, found := document["schema_version"]
+	if !found {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%w: schema_version is not a number", ErrInvalidSchemaVersion)
+	}
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSchemaVersion, number)
+	}
+	if version > SchemaVersion {
+		return int(version), fmt.Errorf(
+			"%w: %d is newer than %d, upgrade flextime to read this file",
+			ErrInvalidSchemaVersion,
+			version,
+			SchemaVersion,
+		)
+	}
+	return int(version), nil
+}
+
+// MigrateDocument upgrades a JSON document of the DB, as decoded with numbers as json.Number,
+// step by step to SchemaVersion. Documents from a newer version are refused, see schemaVersionOf.
+func MigrateDocument(document map[string]any) (MigrationReport, error) {
+	version, err := schemaVersionOf(document)
+	report := MigrationReport{From: version, To: version, Changes: make([]string, 0)}
+	if err != nil {
+		return report, err
+	}
+	for _, migration := range migrations {
+		if migration.From < report.To {
+			continue
+		}
+		changes, err := migration.Migrate(document)
+		if err != nil {
+			return report, fmt.Errorf("migrating from schema version %d: %w", migration.From, err)
+		}
+		report.To = migration.From + 1
+		report.Changes = append(report.Changes, fmt.Sprintf("%d -> %d: %s", migration.From, report.To, migration.Description))
+		for _, change := range changes {
+			report.Changes = append(report.Changes, "\t"+change)
+		}
+		document["schema_version"] = json.Number(fmt.Sprint(report.To))
+	}
+	return report, nil
+}
+
+// MigrateJSON reads a JSON document of the DB and migrates it, see MigrateDocument.
+// Returns the migrated document as JSON. Empty input gives an empty document.
+func MigrateJSON(reader io.Reader) ([]byte, MigrationReport, error) {
+	document, err := decodeDocument(reader)
+	if err != nil {
+		return nil, MigrationReport{}, err
+	}
+	report, err := MigrateDocument(document)
+	if err != nil {
+		return nil, report, err
+	}
+	buffer := bytes.Buffer{}
+	if err = json.NewEncoder(&buffer).Encode(document); err != nil {
+		return nil, report, err
+	}
+	return buffer.Bytes(), report, nil
+}
+
+// decodeDocument reads a JSON document of the DB, with numbers as json.Number.
+// Empty input gives an empty document.
+func decodeDocument(reader io.Reader) (map[string]any, error) {
+	document := make(map[string]any)
+	decoder := json.NewDecoder(reader)
+	// Durations are nanoseconds, too many for the precision of float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return document, nil
+}
+
+// documentCustomers returns the customers of the document, skipping anything that isn't one
+func documentCustomers(document map[string]any) []map[string]any {
+	list, _ := document["customers"].([]any)
+	customers := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if customer, ok := item.(map[string]any); ok {
+			customers = append(customers, customer)
+		}
+	}
+	return customers
+}
+
+// migrateEntryIDs gives an ID to each entry without one, as entries had no IDs before schema version 1
+func migrateEntryIDs(document map[string]any) ([]string, error) {
+	changes := make([]string, 0)
+	for _, customer := range documentCustomers(document) {
+		entries, _ := customer["flex_entries"].([]any)
+		used := make(map[string]bool)
+		for _, item := range entries {
+			if entry, ok := item.(map[string]any); ok {
+				if id, ok := entry["id"].(string); ok && id != "" {
+					used[id] = true
+				}
+			}
+		}
+		assigned := 0
+		for _, item := range entries {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if id, ok := entry["id"].(string); ok && id != "" {
+				continue
+			}
+			id := NewEntryID()
+			for used[id] {
+				id = NewEntryID()
+			}
+			used[id] = true
+			entry["id"] = id
+			assigned++
+		}
+		if assigned > 0 {
+			changes = append(changes, fmt.Sprintf("%v: gave %d of %d entries an ID", customer["customer_name"], assigned, len(entries)))
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,71 @@
+package flex
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationsRegistry(t *testing.T) {
+	// There must be exactly one migration from each version before the current
+	registered := Migrations()
+	if assert.Len(t, registered, SchemaVersion) {
+		for idx, migration := range registered {
+			assert.Equal(t, idx, migration.From)
+			assert.NotEmpty(t, migration.Description)
+		}
+	}
+}
+
+func TestMigrateJSON(t *testing.T) {
+	input := `{"customers":[{"customer_name":"acme","flex_entries":[{"id":"a1","amount":9223372036854775807},{"amount":1},{"amount":2}]}]}`
+	data, report, err := MigrateJSON(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.True(t, report.NeedsMigration())
+	assert.Equal(t, 0, report.From)
+	assert.Equal(t, SchemaVersion, report.To)
+	assert.Equal(t, []string{"0 -> 1: give every entry an ID", "\tacme: gave 2 of 3 entries an ID"}, report.Changes)
+
+	db := &DB{}
+	assert.NoError(t, json.Unmarshal(data, db))
+	assert.Equal(t, SchemaVersion, db.SchemaVersion)
+	entries := db.Customers[0].Entries
+	assert.Equal(t, "a1", entries[0].ID)
+	// large numbers must survive the round trip through the document
+	assert.Equal(t, int64(9223372036854775807), int64(entries[0].Amount))
+	assert.NotEmpty(t, entries[1].ID)
+	assert.NotEmpty(t, entries[2].ID)
+	assert.NotEqual(t, entries[1].ID, entries[2].ID)
+
+	// Migrating again changes nothing
+	_, report, err = MigrateJSON(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	assert.False(t, report.NeedsMigration())
+	assert.Empty(t, report.Changes)
+}
+
+func TestMigrateJSONInvalidVersion(t *testing.T) {
+	for _, input := range []string{`{"schema_version":999}`, `{"schema_version":"1"}`, `{"schema_version":-1}`} {
+		_, _, err := MigrateJSON(strings.NewReader(input))
+		assert.ErrorIs(t, err, ErrInvalidSchemaVersion, input)
+	}
+
+	_, err := DecodeDB(strings.NewReader(`{"schema_version":999,"customers":[{"customer_name":"acme"}]}`))
+	assert.ErrorIs(t, err, ErrInvalidSchemaVersion)
+}
+
+func TestDecodeDBOutdated(t *testing.T) {
+	input := `{"customers":[{"customer_name":"acme","flex_entries":[{"amount":1}]}]}`
+	db, err := DecodeDB(strings.NewReader(input))
+	assert.ErrorIs(t, err, ErrOutdatedSchemaVersion)
+	assert.Nil(t, db)
+
+	// Read leniently, the DB is returned as it is, without being migrated
+	db, _, err = DecodeDBLenient(strings.NewReader(input))
+	assert.ErrorIs(t, err, ErrOutdatedSchemaVersion)
+	if assert.NotNil(t, db) {
+		assert.Empty(t, db.Customers[0].Entries[0].ID)
+	}
+}
@@ -0,0 +1,220 @@
+package flex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Period is the length of time entries are grouped by in reports
+type Period uint8
+
+const (
+	PeriodDay Period = iota
+	PeriodWeek
+	PeriodMonth
+	PeriodQuarter
+	PeriodYear
+)
+
+var periodNames = map[Period]string{
+	PeriodDay:     "day",
+	PeriodWeek:    "week",
+	PeriodMonth:   "month",
+	PeriodQuarter: "quarter",
+	PeriodYear:    "year",
+}
+
+// A PeriodTotal is the sum of the entries within one period.
+// Balance is the running balance at the end of the period, including all earlier periods
+// and any opening balance.
+type PeriodTotal struct {
+	Period  Period
+	Start   time.Time // first date of the period
+	End     time.Time // first date after the period
+	Count   int
+	Amount  time.Duration
+	Balance time.Duration
+}
+
+type PeriodTotals []PeriodTotal
+
+// ParsePeriod returns the Period matching the given name
+func ParsePeriod(name string) (Period, error) {
+	for period, periodName := range periodNames {
+		if strings.EqualFold(name, periodName) {
+			return period, nil
+		}
+	}
+	return PeriodDay, fmt.Errorf("%w: %q", ErrInvalidPeriod, name)
+}
+
+// PeriodOptions returns the valid names for Period, for use in help texts
+func PeriodOptions() string {
+	return strings.Join(
+		[]string{
+			PeriodDay.String(),
+			PeriodWeek.String(),
+			PeriodMonth.String(),
+			PeriodQuarter.String(),
+			PeriodYear.String(),
+		},
+		", ",
+	)
+}
+
+func (period Period) String() string {
+	if name, ok := periodNames[period]; ok {
+		return name
+	}
+	return fmt.Sprintf("Period(%d)", period)
+}
+
+// Start returns the first date of the period that the given date is within.
+// Weeks are ISO 8601 weeks, starting on Monday.
+func (period Period) Start(date time.Time) time.Time {
+	date = DateOf(date)
+	year, month, _ := date.Date()
+	switch period {
+	case PeriodWeek:
+		// Monday is 0 steps back, Sunday 6
+		return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	case PeriodMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+// Next returns the first date of the period following the one the given date is within
+func (period Period) Next(date time.Time) time.Time {
+	start := period.Start(date)
+	switch period {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		return start.AddDate(0, 3, 0)
+	case PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Label returns a short name for the period the given date is within,
+// e.g. "2022-01-03", "2022-W01", "2022-01", "2022-Q1" or "2022"
+func (period Period) Label(date time.Time) string {
+	date = DateOf(date)
+	switch period {
+	case PeriodWeek:
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonth:
+		return date.Format("2006-01")
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1)
+	case PeriodYear:
+		return date.Format("2006")
+	default:
+		return date.Format(ShortDateFormat)
+	}
+}
+
+// Label returns a short name for the period, see Period.Label
+func (total PeriodTotal) Label() string {
+	return total.Period.Label(total.Start)
+}
+
+// GroupByPeriod sums the entries per period, and returns the totals for each period with any entries,
+// sorted by date. The running balance starts from the given opening balance, which would be the
+// sum of any entries before the first one given.
+func (entries Entries) GroupByPeriod(period Period, opening time.Duration) PeriodTotals {
+	totals := make(map[time.Time]*PeriodTotal)
+	for _, entry := range entries {
+		start := period.Start(entry.Date)
+		total, ok := totals[start]
+		if !ok {
+			total = &PeriodTotal{Period: period, Start: start, End: period.Next(start)}
+			totals[start] = total
+		}
+		total.Count++
+		total.Amount += entry.Amount
+	}
+
+	result := make(PeriodTotals, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	balance := opening
+	for idx := range result {
+		balance += result[idx].Amount
+		result[idx].Balance = balance
+	}
+
+	return result
+}
+
+// BalanceBefore returns the sum of all entries dated before the given date
+func (entries Entries) BalanceBefore(date time.Time) time.Duration {
+	var balance time.Duration
+	for _, entry := range entries {
+		if entry.Date.Before(date) {
+			balance += entry.Amount
+		}
+	}
+	return balance
+}
+
+// Fill returns the totals with empty periods inserted where there are gaps,
+// so that every period from the first to the last is included.
+// The balance of an empty period is the same as for the one before it.
+func (totals PeriodTotals) Fill() PeriodTotals {
+	if len(totals) == 0 {
+		return totals
+	}
+	filled := make(PeriodTotals, 0, len(totals))
+	for idx, total := range totals {
+		if idx > 0 {
+			previous := filled[len(filled)-1]
+			for start := previous.End; start.Before(total.Start); start = total.Period.Next(start) {
+				filled = append(filled, PeriodTotal{
+					Period:  total.Period,
+					Start:   start,
+					End:     total.Period.Next(start),
+					Balance: previous.Balance,
+				})
+			}
+		}
+		filled = append(filled, total)
+	}
+	return filled
+}
+
+// Count returns the number of entries in all periods
+func (totals PeriodTotals) Count() int {
+	count := 0
+	for _, total := range totals {
+		count += total.Count
+	}
+	return count
+}
+
+// Amount returns the sum of all periods
+func (totals PeriodTotals) Amount() time.Duration {
+	var amount time.Duration
+	for _, total := range totals {
+		amount += total.Amount
+	}
+	return amount
+}
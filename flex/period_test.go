@@ -0,0 +1,80 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ymd(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParsePeriod(t *testing.T) {
+	period, err := ParsePeriod("Week")
+	assert.NoError(t, err)
+	assert.Equal(t, PeriodWeek, period)
+
+	_, err = ParsePeriod("fortnight")
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestPeriodStartNextLabel(t *testing.T) {
+	tests := []struct {
+		period Period
+		date   time.Time
+		start  time.Time
+		next   time.Time
+		label  string
+	}{
+		{PeriodDay, ymd(2022, 3, 15), ymd(2022, 3, 15), ymd(2022, 3, 16), "2022-03-15"},
+		// Sunday belongs to the week starting the Monday before
+		{PeriodWeek, ymd(2022, 3, 20), ymd(2022, 3, 14), ymd(2022, 3, 21), "2022-W11"},
+		{PeriodWeek, ymd(2022, 3, 21), ymd(2022, 3, 21), ymd(2022, 3, 28), "2022-W12"},
+		// ISO week 52 of 2021 ends on January 2nd 2022
+		{PeriodWeek, ymd(2022, 1, 2), ymd(2021, 12, 27), ymd(2022, 1, 3), "2021-W52"},
+		{PeriodMonth, ymd(2022, 12, 31), ymd(2022, 12, 1), ymd(2023, 1, 1), "2022-12"},
+		{PeriodQuarter, ymd(2022, 6, 30), ymd(2022, 4, 1), ymd(2022, 7, 1), "2022-Q2"},
+		{PeriodQuarter, ymd(2022, 11, 1), ymd(2022, 10, 1), ymd(2023, 1, 1), "2022-Q4"},
+		{PeriodYear, ymd(2022, 6, 30), ymd(2022, 1, 1), ymd(2023, 1, 1), "2022"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.start, tt.period.Start(tt.date), "%s start of %s", tt.period, tt.date)
+		assert.Equal(t, tt.next, tt.period.Next(tt.date), "%s next of %s", tt.period, tt.date)
+		assert.Equal(t, tt.label, tt.period.Label(tt.date), "%s label of %s", tt.period, tt.date)
+	}
+}
+
+func TestEntriesGroupByPeriod(t *testing.T) {
+	entries := Entries{
+		{Date: ymd(2022, 2, 1), Amount: 30 * time.Minute},
+		{Date: ymd(2022, 1, 3), Amount: time.Hour},
+		{Date: ymd(2022, 1, 31), Amount: -15 * time.Minute},
+		{Date: ymd(2022, 4, 4), Amount: time.Hour},
+	}
+
+	totals := entries.GroupByPeriod(PeriodMonth, time.Hour)
+	if assert.Len(t, totals, 3) {
+		assert.Equal(t, "2022-01", totals[0].Label())
+		assert.Equal(t, 2, totals[0].Count)
+		assert.Equal(t, 45*time.Minute, totals[0].Amount)
+		assert.Equal(t, time.Hour+45*time.Minute, totals[0].Balance)
+		assert.Equal(t, "2022-02", totals[1].Label())
+		assert.Equal(t, 2*time.Hour+15*time.Minute, totals[1].Balance)
+		assert.Equal(t, "2022-04", totals[2].Label())
+		assert.Equal(t, 3*time.Hour+15*time.Minute, totals[2].Balance)
+	}
+	assert.Equal(t, 4, totals.Count())
+	assert.Equal(t, 2*time.Hour+15*time.Minute, totals.Amount())
+
+	filled := totals.Fill()
+	if assert.Len(t, filled, 4) {
+		assert.Equal(t, "2022-03", filled[2].Label())
+		assert.Equal(t, 0, filled[2].Count)
+		assert.Equal(t, filled[1].Balance, filled[2].Balance)
+	}
+
+	assert.Empty(t, Entries{}.GroupByPeriod(PeriodWeek, 0))
+	assert.Equal(t, 45*time.Minute, entries.BalanceBefore(ymd(2022, 2, 1)))
+}
@@ -0,0 +1,216 @@
+package flex
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A Schedule holds the expected amount of work for each day of the week
+type Schedule struct {
+	Monday    time.Duration `json:"monday"`
+	Tuesday   time.Duration `json:"tuesday"`
+	Wednesday time.Duration `json:"wednesday"`
+	Thursday  time.Duration `json:"thursday"`
+	Friday    time.Duration `json:"friday"`
+	Saturday  time.Duration `json:"saturday"`
+	Sunday    time.Duration `json:"sunday"`
+}
+
+// Weekdays lists the days of the week in the order they are presented, starting on Monday
+var Weekdays = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"mon":       time.Monday,
+	"monday":    time.Monday,
+	"tue":       time.Tuesday,
+	"tuesday":   time.Tuesday,
+	"wed":       time.Wednesday,
+	"wednesday": time.Wednesday,
+	"thu":       time.Thursday,
+	"thursday":  time.Thursday,
+	"fri":       time.Friday,
+	"friday":    time.Friday,
+	"sat":       time.Saturday,
+	"saturday":  time.Saturday,
+	"sun":       time.Sunday,
+	"sunday":    time.Sunday,
+}
+
+// StandardSchedule returns a Schedule with the given workday length Monday to Friday,
+// and nothing expected on weekends.
+func StandardSchedule(workday time.Duration) *Schedule {
+	return &Schedule{
+		Monday:    workday,
+		Tuesday:   workday,
+		Wednesday: workday,
+		Thursday:  workday,
+		Friday:    workday,
+	}
+}
+
+// ParseWeekday returns the weekday matching the given english name or three letter abbreviation
+func ParseWeekday(name string) (time.Weekday, error) {
+	weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return time.Sunday, fmt.Errorf("%w: %q", ErrInvalidWeekday, name)
+	}
+	return weekday, nil
+}
+
+// ParseSchedule parses a comma separated list of day=duration pairs into a Schedule.
+// A day may also be an inclusive range of days, e.g. "mon-thu=8h,fri=6h".
+// Days not mentioned are expected to have no work.
+func ParseSchedule(spec string) (*Schedule, error) {
+	schedule := &Schedule{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		days, amount, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: missing '=' in %q", ErrInvalidSchedule, part)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(amount))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		if duration < 0 {
+			return nil, fmt.Errorf("%w: negative duration in %q", ErrInvalidSchedule, part)
+		}
+		first, last, isRange := strings.Cut(days, "-")
+		from, err := ParseWeekday(first)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			to, err = ParseWeekday(last)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			schedule.Set(day, duration)
+			if day == to {
+				break
+			}
+		}
+	}
+	return schedule, nil
+}
+
+// Expected returns the expected amount of work for the given weekday
+func (schedule Schedule) Expected(weekday time.Weekday) time.Duration {
+	switch weekday {
+	case time.Monday:
+		return schedule.Monday
+	case time.Tuesday:
+		return schedule.Tuesday
+	case time.Wednesday:
+		return schedule.Wednesday
+	case time.Thursday:
+		return schedule.Thursday
+	case time.Friday:
+		return schedule.Friday
+	case time.Saturday:
+		return schedule.Saturday
+	default:
+		return schedule.Sunday
+	}
+}
+
+// Set sets the expected amount of work for the given weekday
+func (schedule *Schedule) Set(weekday time.Weekday, amount time.Duration) {
+	switch weekday {
+	case time.Monday:
+		schedule.Monday = amount
+	case time.Tuesday:
+		schedule.Tuesday = amount
+	case time.Wednesday:
+		schedule.Wednesday = amount
+	case time.Thursday:
+		schedule.Thursday = amount
+	case time.Friday:
+		schedule.Friday = amount
+	case time.Saturday:
+		schedule.Saturday = amount
+	default:
+		schedule.Sunday = amount
+	}
+}
+
+// Weekly returns the sum of expected work for a whole week
+func (schedule Schedule) Weekly() time.Duration {
+	var total time.Duration
+	for _, weekday := range Weekdays {
+		total += schedule.Expected(weekday)
+	}
+	return total
+}
+
+// Workday returns the length of a full workday, which is the longest expected day in the schedule,
+// or DefaultWorkdayLength if no work is expected on any day
+func (schedule Schedule) Workday() time.Duration {
+	var longest time.Duration
+	for _, weekday := range Weekdays {
+		if expected := schedule.Expected(weekday); expected > longest {
+			longest = expected
+		}
+	}
+	if longest == 0 {
+		return DefaultWorkdayLength
+	}
+	return longest
+}
+
+// String returns the schedule in the same format as accepted by ParseSchedule
+func (schedule Schedule) String() string {
+	return schedule.Format(time.Duration.String)
+}
+
+// Format returns the schedule like String, with the expected work of each day formatted by the given function
+func (schedule Schedule) Format(formatDuration func(time.Duration) string) string {
+	parts := make([]string, 0, len(Weekdays))
+	for _, weekday := range Weekdays {
+		parts = append(
+			parts,
+			fmt.Sprintf("%s=%s", strings.ToLower(weekday.String()[:3]), formatDuration(schedule.Expected(weekday))),
+		)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ScheduleFor returns the schedule in effect for the given customer.
+// That is the customers own schedule if set, otherwise the DB default schedule if set,
+// otherwise a StandardSchedule with the DB Workday, or DefaultWorkdayLength if not set.
+func (db *DB) ScheduleFor(customer *Customer) *Schedule {
+	if customer != nil && customer.Schedule != nil {
+		return customer.Schedule
+	}
+	if db.DefaultSchedule != nil {
+		return db.DefaultSchedule
+	}
+	if db.Workday > 0 {
+		return StandardSchedule(db.Workday)
+	}
+	return StandardSchedule(DefaultWorkdayLength)
+}
+
+// ExpectedWork returns the expected amount of work for the given customer on the given date.
+// Days in the DB Calendar override the schedule.
+func (db *DB) ExpectedWork(customer *Customer, date time.Time) time.Duration {
+	if day := db.Calendar.Get(date); day != nil {
+		return day.ExpectedWork()
+	}
+	return db.ScheduleFor(customer).Expected(date.Weekday())
+}
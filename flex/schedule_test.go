@@ -0,0 +1,131 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWeekday(t *testing.T) {
+	weekday, err := ParseWeekday("Thu")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Thursday, weekday)
+
+	weekday, err = ParseWeekday("sunday")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Sunday, weekday)
+
+	_, err = ParseWeekday("thor")
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrInvalidWeekday)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected *Schedule
+		err      error
+	}{
+		{
+			name: "fourDayWeek",
+			spec: "mon-thu=8h,fri=6h",
+			expected: &Schedule{
+				Monday:    8 * time.Hour,
+				Tuesday:   8 * time.Hour,
+				Wednesday: 8 * time.Hour,
+				Thursday:  8 * time.Hour,
+				Friday:    6 * time.Hour,
+			},
+		},
+		{
+			name: "wrappingRange",
+			spec: "fri-mon=4h",
+			expected: &Schedule{
+				Friday:   4 * time.Hour,
+				Saturday: 4 * time.Hour,
+				Sunday:   4 * time.Hour,
+				Monday:   4 * time.Hour,
+			},
+		},
+		{
+			name:     "empty",
+			spec:     "",
+			expected: &Schedule{},
+		},
+		{
+			name: "missingEquals",
+			spec: "mon 8h",
+			err:  ErrInvalidSchedule,
+		},
+		{
+			name: "negative",
+			spec: "mon=-8h",
+			err:  ErrInvalidSchedule,
+		},
+		{
+			name: "invalidDay",
+			spec: "mon-thor=8h",
+			err:  ErrInvalidWeekday,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule)
+		})
+	}
+}
+
+func TestScheduleString(t *testing.T) {
+	schedule := StandardSchedule(8 * time.Hour)
+	parsed, err := ParseSchedule(schedule.String())
+	assert.NoError(t, err)
+	assert.Equal(t, schedule, parsed)
+	assert.Equal(t, 40*time.Hour, parsed.Weekly())
+	assert.Equal(
+		t,
+		"mon=8.00,tue=8.00,wed=8.00,thu=8.00,fri=8.00,sat=0.00,sun=0.00",
+		schedule.Format(func(d time.Duration) string { return DurationFormatDecimal.Format(d, 0) }),
+	)
+}
+
+func TestScheduleWorkday(t *testing.T) {
+	schedule, err := ParseSchedule("mon-thu=7h30m,fri=6h")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*time.Hour+30*time.Minute, schedule.Workday())
+	assert.Equal(t, DefaultWorkdayLength, Schedule{}.Workday())
+}
+
+func TestDBScheduleFor(t *testing.T) {
+	partTime := &Schedule{Monday: 4 * time.Hour}
+	fourDays, _ := ParseSchedule("mon-thu=8h")
+	db := &DB{
+		Customers: Customers{
+			{Name: "Customer1", Schedule: partTime},
+			{Name: "Customer2"},
+		},
+	}
+	monday := time.Date(2021, time.December, 6, 0, 0, 0, 0, time.UTC)
+	friday := time.Date(2021, time.December, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, partTime, db.ScheduleFor(db.Customers[0]))
+	assert.Equal(t, StandardSchedule(DefaultWorkdayLength), db.ScheduleFor(db.Customers[1]))
+	assert.Equal(t, DefaultWorkdayLength, db.ExpectedWork(db.Customers[1], friday))
+
+	db.Workday = 7 * time.Hour
+	assert.Equal(t, StandardSchedule(7*time.Hour), db.ScheduleFor(db.Customers[1]))
+
+	db.DefaultSchedule = fourDays
+	assert.Equal(t, fourDays, db.ScheduleFor(db.Customers[1]))
+	assert.Equal(t, time.Duration(0), db.ExpectedWork(db.Customers[1], friday))
+	assert.Equal(t, 4*time.Hour, db.ExpectedWork(db.Customers[0], monday))
+}
@@ -0,0 +1,132 @@
+package flex
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Session is a running period of work for a Customer, started by clocking in.
+// It is stored with the Customer until stopped, so it survives between invocations.
+type Session struct {
+	Start time.Time `json:"start"`
+}
+
+// Elapsed returns how long the session has been running at the given time
+func (session Session) Elapsed(now time.Time) time.Duration {
+	return now.Sub(session.Start)
+}
+
+// HasSession returns true if the customer has a running session, false otherwise
+func (customer Customer) HasSession() bool {
+	return customer.Session != nil
+}
+
+// StartSession starts a new session for the customer at the given time.
+// Returns an error if a session is already running.
+func (customer *Customer) StartSession(start time.Time) error {
+	if customer.HasSession() {
+		return fmt.Errorf(
+			"%w: %s since %s",
+			ErrSessionRunning,
+			customer.Name,
+			customer.Session.Start.Format(time.RFC3339),
+		)
+	}
+	customer.Session = &Session{Start: start}
+	return nil
+}
+
+// StopSession stops the running session at the given time, and records the worked time
+// on the Entry for the date the session started. The Amount of the Entry is set to
+// the total worked time for that date minus the given workday length.
+// If the date only has entries that were not recorded through sessions, they will only be
+// replaced if overwrite is true, otherwise ErrEntryIsManual is returned and the session keeps running.
+// Returns the updated Entry.
+func (customer *Customer) StopSession(stop time.Time, workday time.Duration, overwrite bool) (*Entry, error) {
+	if !customer.HasSession() {
+		return nil, fmt.Errorf("%w: %s", ErrNoSession, customer.Name)
+	}
+	worked := stop.Sub(customer.Session.Start)
+	if worked < 0 {
+		return nil, fmt.Errorf(
+			"%w: %s < %s",
+			ErrInvalidSession,
+			stop.Format(time.RFC3339),
+			customer.Session.Start.Format(time.RFC3339),
+		)
+	}
+
+	date := DateOf(customer.Session.Start)
+	var entry *Entry
+	entries := customer.Entries.FilterByDate(date)
+	for _, dayEntry := range entries {
+		if dayEntry.Worked != 0 {
+			entry = dayEntry
+			break
+		}
+	}
+	if entry == nil {
+		if entries.Len() > 0 && !overwrite {
+			return nil, fmt.Errorf("%w: %s", ErrEntryIsManual, date.Format(ShortDateFormat))
+		}
+		entry = customer.setEntry(Entry{Date: date}, true)
+	}
+	entry.Worked += worked
+	entry.Amount = entry.Worked - workday
+	customer.Session = nil
+
+	return entry, nil
+}
+
+// StartSession starts a session for the customer with the given name, adding the customer if needed.
+// If customerName is blank, the default customer is used.
+func (db *DB) StartSession(customerName string, start time.Time) (*Customer, error) {
+	customer := db.getOrAddCustomer(customerName)
+	if err := customer.StartSession(start); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// StopSession stops the running session for the customer with the given name.
+// If customerName is blank and exactly one customer has a running session, that session is stopped,
+// otherwise the default customer is used.
+// The expected workday length is taken from the customers schedule for the date the session started.
+// See Customer.StopSession for how the resulting Entry is calculated.
+func (db *DB) StopSession(customerName string, stop time.Time, overwrite bool) (*Customer, *Entry, error) {
+	var customer *Customer
+	var err error
+	if customerName == "" {
+		running := db.CustomersWithSession()
+		if running.Len() == 1 {
+			customer = running[0]
+		} else {
+			customer = db.GetDefaultCustomer()
+		}
+	} else {
+		customer, err = db.GetCustomer(customerName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var workday time.Duration
+	if customer.HasSession() {
+		workday = db.ExpectedWork(customer, customer.Session.Start)
+	}
+	entry, err := customer.StopSession(stop, workday, overwrite)
+	if err != nil {
+		return customer, nil, err
+	}
+	return customer, entry, nil
+}
+
+// CustomersWithSession returns the customers that currently have a running session
+func (db *DB) CustomersWithSession() Customers {
+	running := make(Customers, 0)
+	for _, customer := range db.Customers {
+		if customer.HasSession() {
+			running = append(running, customer)
+		}
+	}
+	return running
+}
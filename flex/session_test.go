@@ -0,0 +1,116 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerStartSession(t *testing.T) {
+	start := time.Date(2021, time.December, 3, 8, 0, 0, 0, time.UTC)
+	customer := &Customer{Name: "Customer1"}
+	assert.False(t, customer.HasSession())
+
+	err := customer.StartSession(start)
+	assert.NoError(t, err)
+	assert.True(t, customer.HasSession())
+
+	err = customer.StartSession(start.Add(time.Hour))
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrSessionRunning)
+	}
+	assert.True(t, start.Equal(customer.Session.Start))
+}
+
+func TestCustomerStopSessionWhenNoSession(t *testing.T) {
+	customer := &Customer{Name: "Customer1"}
+	entry, err := customer.StopSession(time.Now(), DefaultWorkdayLength, false)
+	assert.Nil(t, entry)
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrNoSession)
+	}
+}
+
+func TestCustomerStopSessionBeforeStart(t *testing.T) {
+	start := time.Date(2021, time.December, 3, 8, 0, 0, 0, time.UTC)
+	customer := &Customer{Name: "Customer1", Session: &Session{Start: start}}
+	entry, err := customer.StopSession(start.Add(-time.Minute), DefaultWorkdayLength, false)
+	assert.Nil(t, entry)
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrInvalidSession)
+	}
+	assert.True(t, customer.HasSession())
+}
+
+func TestCustomerStopSession(t *testing.T) {
+	start := time.Date(2021, time.December, 3, 8, 0, 0, 0, time.UTC)
+	customer := &Customer{Name: "Customer1"}
+
+	assert.NoError(t, customer.StartSession(start))
+	entry, err := customer.StopSession(start.Add(4*time.Hour), DefaultWorkdayLength, false)
+	assert.NoError(t, err)
+	assert.False(t, customer.HasSession())
+	if assert.NotNil(t, entry) {
+		assert.True(t, DateOf(start).Equal(entry.Date))
+		assert.Equal(t, 4*time.Hour, entry.Worked)
+		assert.Equal(t, -4*time.Hour, entry.Amount)
+	}
+
+	// a second session the same day adds to the worked time
+	assert.NoError(t, customer.StartSession(start.Add(5*time.Hour)))
+	entry, err = customer.StopSession(start.Add(10*time.Hour+30*time.Minute), DefaultWorkdayLength, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, 9*time.Hour+30*time.Minute, entry.Worked)
+		assert.Equal(t, 90*time.Minute, entry.Amount)
+	}
+	assert.Equal(t, 1, customer.Entries.Len())
+	assert.Equal(t, 90*time.Minute, customer.GetTotalFlex())
+}
+
+func TestCustomerStopSessionWithManualEntry(t *testing.T) {
+	start := time.Date(2021, time.December, 3, 8, 0, 0, 0, time.UTC)
+	customer := &Customer{
+		Name: "Customer1",
+		Entries: Entries{
+			{Date: DateOf(start), Amount: time.Hour},
+		},
+		Session: &Session{Start: start},
+	}
+
+	entry, err := customer.StopSession(start.Add(9*time.Hour), DefaultWorkdayLength, false)
+	assert.Nil(t, entry)
+	if assert.Error(t, err) {
+		assert.ErrorIs(t, err, ErrEntryIsManual)
+	}
+	assert.True(t, customer.HasSession())
+
+	entry, err = customer.StopSession(start.Add(9*time.Hour), DefaultWorkdayLength, true)
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, time.Hour, entry.Amount)
+		assert.Equal(t, 9*time.Hour, entry.Worked)
+	}
+	assert.Equal(t, 1, customer.Entries.Len())
+}
+
+func TestDBStopSessionPicksOnlyRunningSession(t *testing.T) {
+	start := time.Date(2021, time.December, 3, 8, 0, 0, 0, time.UTC)
+	db := NewDB()
+	_, err := db.AddCustomer("Customer1")
+	assert.NoError(t, err)
+	_, err = db.StartSession("Customer2", start)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, db.CustomersWithSession().Len())
+
+	customer, entry, err := db.StopSession("", start.Add(8*time.Hour), false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, customer) {
+		assert.Equal(t, "Customer2", customer.Name)
+	}
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, time.Duration(0), entry.Amount)
+	}
+	assert.Equal(t, 0, db.CustomersWithSession().Len())
+}
@@ -0,0 +1,235 @@
+package flex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	timeclockDateFormat = "2006/01/02"
+	timeclockTimeFormat = "15:04:05"
+)
+
+// parseTimeclockTime parses the date and time of a clock in or out line, in the given location.
+// Dates may be written with / or -, and seconds may be left out.
+func parseTimeclockTime(date, clock string, location *time.Location) (time.Time, error) {
+	date = strings.ReplaceAll(date, "-", "/")
+	layout := timeclockDateFormat + " " + timeclockTimeFormat
+	if strings.Count(clock, ":") == 1 {
+		layout = timeclockDateFormat + " " + ClockFormat
+	}
+	t, err := time.ParseInLocation(layout, date+" "+clock, location)
+	if err != nil {
+		return t, fmt.Errorf("%w: invalid time %q", ErrInvalidTimeclock, date+" "+clock)
+	}
+	return t, nil
+}
+
+// skipFields returns what follows the given number of whitespace separated fields of the text,
+// as split by strings.Fields
+func skipFields(text string, count int) string {
+	for ; count > 0; count-- {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		idx := strings.IndexFunc(text, unicode.IsSpace)
+		if idx == -1 {
+			return ""
+		}
+		text = text[idx:]
+	}
+	return text
+}
+
+// splitTimeclockAccount splits what follows the time of a clock in line into account and description,
+// which are separated by two or more spaces, or a tab
+func splitTimeclockAccount(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexByte(text, '\t'); idx != -1 {
+		return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx:])
+	}
+	if account, description, found := strings.Cut(text, "  "); found {
+		return strings.TrimSpace(account), strings.TrimSpace(description)
+	}
+	return text, ""
+}
+
+// ReadTimeclock reads the intervals between clock in (i) and clock out (o) lines of a timeclock file,
+// as used by ledger and hledger, with times in the given location.
+// The tag of an interval is the last part of its account, like acme for work:acme.
+// The annotation is the description of the clock in line, or else of the clock out line.
+// Lines that don't make sense, like clocking out without clocking in, are returned as rejected.
+func ReadTimeclock(reader io.Reader, location *time.Location) ([]TimeInterval, []RejectedRow, error) {
+	intervals := make([]TimeInterval, 0)
+	rejected := make([]RejectedRow, 0)
+	var open *TimeInterval
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		code := fields[0]
+		if code != "i" && code != "o" && code != "I" && code != "O" {
+			// comments, and the other codes, which say nothing about time worked
+			continue
+		}
+		if len(fields) < 3 {
+			rejected = append(rejected, RejectedRow{Line: line, Err: fmt.Errorf("%w: missing date or time", ErrInvalidTimeclock)})
+			continue
+		}
+		at, err := parseTimeclockTime(fields[1], fields[2], location)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: err})
+			continue
+		}
+		// what follows the code, date and time
+		rest := skipFields(text, 3)
+
+		if strings.EqualFold(code, "i") {
+			if open != nil {
+				rejected = append(rejected, RejectedRow{Line: open.Line, Err: fmt.Errorf("%w: clocked in again before clocking out", ErrInvalidTimeclock)})
+			}
+			account, description := splitTimeclockAccount(rest)
+			tags := make([]string, 0, 1)
+			if name := account[strings.LastIndex(account, ":")+1:]; name != "" {
+				tags = append(tags, name)
+			}
+			open = &TimeInterval{Line: line, Start: at, Tags: tags, Annotation: description}
+			continue
+		}
+
+		if open == nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: fmt.Errorf("%w: clocked out without clocking in", ErrInvalidTimeclock)})
+			continue
+		}
+		if !at.After(open.Start) {
+			rejected = append(rejected, RejectedRow{Line: line, Err: fmt.Errorf("%w: clocked out before clocking in", ErrInvalidTimeclock)})
+			open = nil
+			continue
+		}
+		open.End = at
+		if open.Annotation == "" {
+			open.Annotation = strings.TrimSpace(rest)
+		}
+		intervals = append(intervals, *open)
+		open = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if open != nil {
+		rejected = append(rejected, RejectedRow{Line: open.Line, Err: fmt.Errorf("%w: never clocked out", ErrInvalidTimeclock)})
+	}
+	return intervals, rejected, nil
+}
+
+// TimeclockOptions tells WriteTimeclock how to write entries
+type TimeclockOptions struct {
+	Account string        // account prefix, the customer name is added as the last part, like work:acme
+	Start   time.Duration // time of day to clock in, as entries only have dates
+	// FormatDuration formats the flex of dates with no time worked, written as comments.
+	// If nil, time.Duration.String is used.
+	FormatDuration func(time.Duration) string
+}
+
+// timeclockDay is what WriteTimeclock writes for a date
+type timeclockDay struct {
+	date     time.Time
+	worked   time.Duration // summed time worked of the entries that have it
+	adjusted time.Duration // summed flex amounts of the entries without time worked
+	amount   time.Duration // summed flex amounts of all the entries
+	comments []string
+}
+
+// timeclockDays sums up the entries per date, in date order
+func timeclockDays(entries Entries) []*timeclockDay {
+	days := make([]*timeclockDay, 0)
+	byDate := make(map[time.Time]*timeclockDay)
+	for _, entry := range entries {
+		date := DateOf(entry.Date)
+		day, found := byDate[date]
+		if !found {
+			day = &timeclockDay{date: date}
+			byDate[date] = day
+			days = append(days, day)
+		}
+		if entry.Worked != 0 {
+			day.worked += entry.Worked
+		} else {
+			day.adjusted += entry.Amount
+		}
+		day.amount += entry.Amount
+		if entry.Comment != "" {
+			day.comments = append(day.comments, strings.ReplaceAll(entry.Comment, "\n", " "))
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].date.Before(days[j].date)
+	})
+	return days
+}
+
+// WriteTimeclock writes the entries of the customer as timeclock records, for ledger or hledger.
+// Entries have no times, so each date with entries is written as one session, clocking in at options.Start,
+// or when the previous session ended if that's later, and out after the time worked on the date.
+// That is the time worked of the entries that have it, or else the expected work for the date,
+// plus the flex amounts of the entries without time worked, as several entries can share a date.
+// Dates with no time worked are written as comments, to keep the balance traceable.
+func (db *DB) WriteTimeclock(writer io.Writer, customer *Customer, entries Entries, options TimeclockOptions) error {
+	account := customer.Name
+	if options.Account != "" {
+		account = strings.TrimSuffix(options.Account, ":") + ":" + customer.Name
+	}
+	formatDuration := options.FormatDuration
+	if formatDuration == nil {
+		formatDuration = time.Duration.String
+	}
+	var lastOut time.Time
+	for _, day := range timeclockDays(entries) {
+		worked := day.worked
+		if worked == 0 {
+			worked = db.ExpectedWork(customer, day.date)
+		}
+		worked += day.adjusted
+		if worked <= 0 {
+			if _, err := fmt.Fprintf(
+				writer,
+				"; %s %s: no time worked, flex %s\n",
+				day.date.Format(timeclockDateFormat),
+				account,
+				formatDuration(day.amount),
+			); err != nil {
+				return err
+			}
+			continue
+		}
+		in := day.date.Add(options.Start)
+		if in.Before(lastOut) {
+			in = lastOut
+		}
+		out := in.Add(worked)
+		lastOut = out
+		description := ""
+		if len(day.comments) > 0 {
+			description = "  " + strings.Join(day.comments, "; ")
+		}
+		if _, err := fmt.Fprintf(
+			writer,
+			"i %s %s%s\no %s\n\n",
+			in.Format(timeclockDateFormat+" "+timeclockTimeFormat),
+			account,
+			description,
+			out.Format(timeclockDateFormat+" "+timeclockTimeFormat),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
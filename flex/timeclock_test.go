@@ -0,0 +1,112 @@
+package flex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTimeclock(t *testing.T) {
+	input := strings.Join(
+		[]string{
+			"; a comment",
+			"i 2022/01/03 08:00:00 work:acme  code review",
+			"o 2022/01/03 12:30:00",
+			"",
+			"i 2022-01-04 09:00 acme",
+			"o 2022-01-04 08:00",
+			"o 2022/01/05 16:00:00",
+			"i 2022/01/06 08:00:00 acme",
+			"o\t2022/01/06\t12:00\tdone at 12:00",
+			"i 2022/01/07 08:00:00 acme",
+		},
+		"\n",
+	)
+	location := time.FixedZone("CET", 3600)
+	intervals, rejected, err := ReadTimeclock(strings.NewReader(input), location)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]TimeInterval{
+			{
+				Line:       2,
+				Start:      time.Date(2022, 1, 3, 8, 0, 0, 0, location),
+				End:        time.Date(2022, 1, 3, 12, 30, 0, 0, location),
+				Tags:       []string{"acme"},
+				Annotation: "code review",
+			},
+			{
+				Line:       8,
+				Start:      time.Date(2022, 1, 6, 8, 0, 0, 0, location),
+				End:        time.Date(2022, 1, 6, 12, 0, 0, 0, location),
+				Tags:       []string{"acme"},
+				Annotation: "done at 12:00",
+			},
+		},
+		intervals,
+	)
+	lines := make([]int, 0, len(rejected))
+	for _, row := range rejected {
+		assert.ErrorIs(t, row.Err, ErrInvalidTimeclock)
+		lines = append(lines, row.Line)
+	}
+	assert.Equal(t, []int{6, 7, 10}, lines)
+}
+
+func TestWriteTimeclock(t *testing.T) {
+	db := transferTestDB()
+	customer := db.Customers[1] // right, with a 6h schedule
+	customer.Entries = append(
+		customer.Entries,
+		&Entry{Date: ymd(2022, 1, 5), Worked: 7 * time.Hour, Amount: time.Hour, Comment: "release"},
+		&Entry{Date: ymd(2022, 1, 6), Amount: -6 * time.Hour},
+	)
+
+	builder := strings.Builder{}
+	err := db.WriteTimeclock(&builder, customer, customer.Entries, TimeclockOptions{Account: "work", Start: 8 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"i 2022/01/04 08:00:00 work:right\no 2022/01/04 13:00:00\n\n"+
+			"i 2022/01/05 08:00:00 work:right  release\no 2022/01/05 15:00:00\n\n"+
+			"; 2022/01/06 work:right: no time worked, flex -6h0m0s\n",
+		builder.String(),
+	)
+
+	// What is written can be read back
+	intervals, rejected, err := ReadTimeclock(strings.NewReader(builder.String()), time.UTC)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	if assert.Len(t, intervals, 2) {
+		assert.Equal(t, []string{"right"}, intervals[1].Tags)
+		assert.Equal(t, "release", intervals[1].Annotation)
+	}
+}
+
+func TestWriteTimeclockSharedDates(t *testing.T) {
+	db := transferTestDB()
+	customer := db.Customers[1] // right, with a 6h schedule, and -1h on 2022-01-04
+	customer.Entries = append(
+		customer.Entries,
+		&Entry{Date: ymd(2022, 1, 7), Amount: 30 * time.Minute},
+		&Entry{Date: ymd(2022, 1, 4), Amount: 30 * time.Minute},
+		&Entry{Date: ymd(2022, 1, 5), Worked: 7 * time.Hour, Amount: time.Hour, Comment: "release"},
+		&Entry{Date: ymd(2022, 1, 5), Amount: 30 * time.Minute, Comment: "review"},
+		&Entry{Date: ymd(2022, 1, 6), Amount: 20 * time.Hour},
+	)
+
+	builder := strings.Builder{}
+	err := db.WriteTimeclock(&builder, customer, customer.Entries, TimeclockOptions{Start: 8 * time.Hour})
+	assert.NoError(t, err)
+	// One session per date, and a session running past midnight delays the next one
+	assert.Equal(
+		t,
+		"i 2022/01/04 08:00:00 right\no 2022/01/04 13:30:00\n\n"+
+			"i 2022/01/05 08:00:00 right  release; review\no 2022/01/05 15:30:00\n\n"+
+			"i 2022/01/06 08:00:00 right\no 2022/01/07 10:00:00\n\n"+
+			"i 2022/01/07 10:00:00 right\no 2022/01/07 16:30:00\n\n",
+		builder.String(),
+	)
+}
@@ -0,0 +1,178 @@
+package flex
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const timewarriorTimeFormat = "20060102T150405Z"
+
+// timewarriorInterval is an interval as written by "timew export"
+type timewarriorInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation"`
+}
+
+// ReadTimewarrior reads the closed intervals from either the output of "timew export",
+// or a Timewarrior data file, like ~/.timewarrior/data/2022-01.data.
+// Open intervals, still being tracked, are left out. For JSON, the line of an interval is its position in the list.
+// Intervals that can't be read are returned as rejected, along with the ones that could.
+func ReadTimewarrior(reader io.Reader) ([]TimeInterval, []RejectedRow, error) {
+	buffered := bufio.NewReader(reader)
+	for {
+		char, _, err := buffered.ReadRune()
+		if err == io.EOF {
+			return make([]TimeInterval, 0), make([]RejectedRow, 0), nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if char == ' ' || char == '\t' || char == '\r' || char == '\n' || char == '\uFEFF' {
+			continue
+		}
+		if err = buffered.UnreadRune(); err != nil {
+			return nil, nil, err
+		}
+		if char == '[' {
+			return readTimewarriorJSON(buffered)
+		}
+		return readTimewarriorData(buffered)
+	}
+}
+
+func readTimewarriorJSON(reader io.Reader) ([]TimeInterval, []RejectedRow, error) {
+	exported := make([]timewarriorInterval, 0)
+	if err := json.NewDecoder(reader).Decode(&exported); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTimewarrior, err)
+	}
+	intervals := make([]TimeInterval, 0, len(exported))
+	rejected := make([]RejectedRow, 0)
+	for idx, item := range exported {
+		if item.End == "" {
+			continue
+		}
+		interval, err := newTimewarriorInterval(idx+1, item.Start, item.End, item.Tags, item.Annotation)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: idx + 1, Err: err})
+			continue
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, rejected, nil
+}
+
+// readTimewarriorData reads lines like:
+//
+//	inc 20220103T080000Z - 20220103T160000Z # acme "code review" # "the annotation"
+func readTimewarriorData(reader io.Reader) ([]TimeInterval, []RejectedRow, error) {
+	intervals := make([]TimeInterval, 0)
+	rejected := make([]RejectedRow, 0)
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, "inc ") {
+			continue
+		}
+		span, rest, _ := strings.Cut(strings.TrimPrefix(text, "inc "), "#")
+		tagText, annotation, _ := strings.Cut(rest, " # ")
+		start, end, _ := strings.Cut(strings.TrimSpace(span), " - ")
+		if end == "" {
+			continue
+		}
+		annotation, err := unquoteTimewarrior(strings.TrimSpace(annotation))
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: err})
+			continue
+		}
+		tags, err := splitTimewarriorTags(tagText)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: err})
+			continue
+		}
+		interval, err := newTimewarriorInterval(line, start, end, tags, annotation)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Err: err})
+			continue
+		}
+		intervals = append(intervals, interval)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return intervals, rejected, nil
+}
+
+func newTimewarriorInterval(line int, start, end string, tags []string, annotation string) (TimeInterval, error) {
+	interval := TimeInterval{Line: line, Tags: tags, Annotation: annotation}
+	var err error
+	interval.Start, err = time.Parse(timewarriorTimeFormat, strings.TrimSpace(start))
+	if err != nil {
+		return interval, fmt.Errorf("%w: invalid start %q", ErrInvalidTimewarrior, start)
+	}
+	interval.End, err = time.Parse(timewarriorTimeFormat, strings.TrimSpace(end))
+	if err != nil {
+		return interval, fmt.Errorf("%w: invalid end %q", ErrInvalidTimewarrior, end)
+	}
+	if !interval.End.After(interval.Start) {
+		return interval, fmt.Errorf("%w: interval ends before it starts", ErrInvalidTimewarrior)
+	}
+	if interval.Tags == nil {
+		interval.Tags = make([]string, 0)
+	}
+	return interval, nil
+}
+
+// splitTimewarriorTags splits space separated tags, where tags with spaces are quoted
+func splitTimewarriorTags(text string) ([]string, error) {
+	tags := make([]string, 0)
+	current := strings.Builder{}
+	quoted, escaped, inTag := false, false, false
+	for _, char := range text {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\' && quoted:
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+			inTag = true
+		case char == ' ' && !quoted:
+			if inTag {
+				tags = append(tags, current.String())
+				current.Reset()
+				inTag = false
+			}
+		default:
+			current.WriteRune(char)
+			inTag = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote in tags", ErrInvalidTimewarrior)
+	}
+	if inTag {
+		tags = append(tags, current.String())
+	}
+	return tags, nil
+}
+
+// unquoteTimewarrior returns the text without surrounding quotes, if any
+func unquoteTimewarrior(text string) (string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return text, nil
+	}
+	var unquoted string
+	if err := json.Unmarshal([]byte(text), &unquoted); err != nil {
+		return text, fmt.Errorf("%w: invalid annotation %s", ErrInvalidTimewarrior, text)
+	}
+	return unquoted, nil
+}
@@ -0,0 +1,70 @@
+package flex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTimewarriorJSON(t *testing.T) {
+	input := `[
+{"id":3,"start":"20220103T070000Z","end":"20220103T110000Z","tags":["acme","code review"],"annotation":"late"},
+{"id":2,"start":"20220103T120000Z","end":"20220103T150000Z"},
+{"id":1,"start":"20220104T070000Z","tags":["acme"]},
+{"id":0,"start":"20220104T070000Z","end":"yesterday"}
+]`
+	intervals, rejected, err := ReadTimewarrior(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]TimeInterval{
+			{
+				Line:       1,
+				Start:      time.Date(2022, 1, 3, 7, 0, 0, 0, time.UTC),
+				End:        time.Date(2022, 1, 3, 11, 0, 0, 0, time.UTC),
+				Tags:       []string{"acme", "code review"},
+				Annotation: "late",
+			},
+			{
+				Line:  2,
+				Start: time.Date(2022, 1, 3, 12, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 1, 3, 15, 0, 0, 0, time.UTC),
+				Tags:  []string{},
+			},
+		},
+		intervals,
+	)
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, 4, rejected[0].Line)
+		assert.ErrorIs(t, rejected[0].Err, ErrInvalidTimewarrior)
+	}
+
+	_, _, err = ReadTimewarrior(strings.NewReader("[{"))
+	assert.ErrorIs(t, err, ErrInvalidTimewarrior)
+}
+
+func TestReadTimewarriorData(t *testing.T) {
+	input := strings.Join(
+		[]string{
+			`inc 20220103T070000Z - 20220103T110000Z # acme "code review" # "late \"night\""`,
+			`inc 20220103T120000Z - 20220103T150000Z`,
+			`inc 20220104T120000Z - 20220104T110000Z # acme`,
+			`inc 20220105T070000Z # # "open"`,
+		},
+		"\n",
+	)
+	intervals, rejected, err := ReadTimewarrior(strings.NewReader(input))
+	assert.NoError(t, err)
+	if assert.Len(t, intervals, 2) {
+		assert.Equal(t, []string{"acme", "code review"}, intervals[0].Tags)
+		assert.Equal(t, `late "night"`, intervals[0].Annotation)
+		assert.Equal(t, 4*time.Hour, intervals[0].End.Sub(intervals[0].Start))
+		assert.Equal(t, 2, intervals[1].Line)
+		assert.Empty(t, intervals[1].Tags)
+	}
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, 3, rejected[0].Line)
+	}
+}
@@ -0,0 +1,133 @@
+package flex
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TransferPolicy decides what TransferEntries does with entries for dates the target customer already has entries for
+type TransferPolicy uint8
+
+const (
+	TransferFail      TransferPolicy = iota // refuse to transfer anything
+	TransferSkip                            // leave those entries where they are
+	TransferOverwrite                       // replace the targets entries for the date
+	TransferAppend                          // add them alongside the targets entries
+)
+
+var transferPolicyNames = map[TransferPolicy]string{
+	TransferFail:      "fail",
+	TransferSkip:      "skip",
+	TransferOverwrite: "overwrite",
+	TransferAppend:    "append",
+}
+
+// ParseTransferPolicy returns the TransferPolicy matching the given name
+func ParseTransferPolicy(name string) (TransferPolicy, error) {
+	for policy, policyName := range transferPolicyNames {
+		if strings.EqualFold(name, policyName) {
+			return policy, nil
+		}
+	}
+	return TransferFail, fmt.Errorf("%w: %q", ErrInvalidTransferPolicy, name)
+}
+
+// TransferPolicyOptions returns the valid names for TransferPolicy, for use in help texts
+func TransferPolicyOptions() string {
+	return strings.Join(
+		[]string{
+			TransferFail.String(),
+			TransferSkip.String(),
+			TransferOverwrite.String(),
+			TransferAppend.String(),
+		},
+		", ",
+	)
+}
+
+func (policy TransferPolicy) String() string {
+	if name, ok := transferPolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("TransferPolicy(%d)", policy)
+}
+
+// TransferResult tells what TransferEntries did
+type TransferResult struct {
+	Transferred Entries // as stored for the target
+	Skipped     Entries // left with the source, due to TransferSkip
+	Replaced    Entries // removed from the target, due to TransferOverwrite
+}
+
+// TransferEntries moves, or copies if move is false, the given entries of source to target.
+// Moved entries keep their IDs where possible, while copies get new ones.
+// Entries recorded as time worked get their flex amount calculated anew from the targets schedule.
+// Dates that target already has entries for are handled according to policy,
+// where TransferFail returns ErrTransferConflict without changing anything.
+func (db *DB) TransferEntries(
+	source, target *Customer,
+	entries Entries,
+	policy TransferPolicy,
+	move bool,
+) (TransferResult, error) {
+	result := TransferResult{
+		Transferred: make(Entries, 0, entries.Len()),
+		Skipped:     make(Entries, 0),
+		Replaced:    make(Entries, 0),
+	}
+	if source == nil || target == nil {
+		return result, ErrNilCustomer
+	}
+	if source == target {
+		return result, fmt.Errorf("%w: source and target are both %s", ErrInvalidCustomerName, source.Name)
+	}
+
+	conflicts := make(map[time.Time]bool)
+	conflictDates := make([]string, 0)
+	for _, entry := range entries {
+		date := DateOf(entry.Date)
+		if !conflicts[date] && target.Entries.FilterByDate(date).Len() > 0 {
+			conflicts[date] = true
+			conflictDates = append(conflictDates, date.Format(ShortDateFormat))
+		}
+	}
+	if len(conflicts) > 0 && policy == TransferFail {
+		return result, fmt.Errorf("%w: %s already has entries for %s", ErrTransferConflict, target.Name, strings.Join(conflictDates, ", "))
+	}
+	if policy == TransferOverwrite {
+		for date := range conflicts {
+			result.Replaced = append(result.Replaced, target.Entries.FilterByDate(date)...)
+			target.Entries.DeleteByDate(date)
+		}
+		result.Replaced.Sort(EntrySortByDateAscending)
+	}
+
+	moved := make(map[*Entry]bool)
+	for _, entry := range entries {
+		if policy == TransferSkip && conflicts[DateOf(entry.Date)] {
+			result.Skipped = append(result.Skipped, entry)
+			continue
+		}
+		transferred := *entry
+		if !move {
+			transferred.ID = ""
+		}
+		if transferred.Worked != 0 {
+			transferred.Amount = transferred.Worked - db.ExpectedWork(target, transferred.Date)
+		}
+		result.Transferred = append(result.Transferred, target.AddEntry(transferred))
+		moved[entry] = true
+	}
+
+	if move {
+		remaining := make(Entries, 0, source.Entries.Len())
+		for _, entry := range source.Entries {
+			if !moved[entry] {
+				remaining = append(remaining, entry)
+			}
+		}
+		source.Entries = remaining
+	}
+	return result, nil
+}
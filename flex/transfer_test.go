@@ -0,0 +1,101 @@
+package flex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// transferTestDB returns a DB where wrong has entries on the 3rd and 4th, and right on the 4th
+func transferTestDB() *DB {
+	return &DB{
+		Customers: Customers{
+			{
+				Name: "wrong",
+				Entries: Entries{
+					{ID: "w1", Date: ymd(2022, 1, 3), Worked: 9 * time.Hour, Amount: time.Hour}, // a Monday
+					{ID: "r1", Date: ymd(2022, 1, 4), Amount: 30 * time.Minute},
+				},
+			},
+			{
+				Name:     "right",
+				Schedule: StandardSchedule(6 * time.Hour),
+				Entries: Entries{
+					{ID: "r1", Date: ymd(2022, 1, 4), Amount: -time.Hour},
+				},
+			},
+		},
+	}
+}
+
+func TestParseTransferPolicy(t *testing.T) {
+	policy, err := ParseTransferPolicy("Overwrite")
+	assert.NoError(t, err)
+	assert.Equal(t, TransferOverwrite, policy)
+
+	_, err = ParseTransferPolicy("merge")
+	assert.ErrorIs(t, err, ErrInvalidTransferPolicy)
+}
+
+func TestTransferEntries(t *testing.T) {
+	tests := []struct {
+		policy      TransferPolicy
+		move        bool
+		sourceLeft  int
+		targetTotal time.Duration
+		skipped     int
+		replaced    int
+	}{
+		// the worked entry is 9h - 6h = 3h for right
+		{TransferOverwrite, true, 0, 3*time.Hour + 30*time.Minute, 0, 1},
+		{TransferSkip, true, 1, 2 * time.Hour, 1, 0},
+		{TransferAppend, true, 0, 2*time.Hour + 30*time.Minute, 0, 0},
+		{TransferAppend, false, 2, 2*time.Hour + 30*time.Minute, 0, 0},
+	}
+	for _, tt := range tests {
+		db := transferTestDB()
+		source, target := db.Customers[0], db.Customers[1]
+
+		result, err := db.TransferEntries(source, target, source.Entries, tt.policy, tt.move)
+		if !assert.NoError(t, err, tt.policy) {
+			continue
+		}
+		assert.Equal(t, tt.sourceLeft, source.Entries.Len(), "%s move=%t", tt.policy, tt.move)
+		assert.Equal(t, tt.targetTotal, target.GetTotalFlex(), "%s move=%t", tt.policy, tt.move)
+		assert.Equal(t, tt.skipped, result.Skipped.Len(), tt.policy)
+		assert.Equal(t, tt.replaced, result.Replaced.Len(), tt.policy)
+		assert.Equal(t, 2-tt.skipped, result.Transferred.Len(), tt.policy)
+
+		ids := make(map[string]bool)
+		for _, entry := range target.Entries {
+			assert.False(t, ids[entry.ID], entry.ID)
+			ids[entry.ID] = true
+		}
+		if tt.move {
+			assert.True(t, ids["w1"], "moved entries keep their IDs")
+		} else {
+			assert.False(t, ids["w1"], "copies get new IDs")
+			// the source is left as it was
+			assert.Equal(t, transferTestDB().Customers[0], source)
+		}
+	}
+}
+
+func TestTransferEntriesFail(t *testing.T) {
+	db := transferTestDB()
+	source, target := db.Customers[0], db.Customers[1]
+
+	_, err := db.TransferEntries(source, target, source.Entries, TransferFail, true)
+	if assert.ErrorIs(t, err, ErrTransferConflict) {
+		assert.Contains(t, err.Error(), "2022-01-04")
+	}
+	assert.Equal(t, transferTestDB(), db)
+
+	// Without conflicting dates, fail is fine
+	_, err = db.TransferEntries(source, target, source.Entries[:1], TransferFail, true)
+	assert.NoError(t, err)
+
+	_, err = db.TransferEntries(source, source, source.Entries, TransferAppend, true)
+	assert.ErrorIs(t, err, ErrInvalidCustomerName)
+}
@@ -0,0 +1,296 @@
+package flex
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FindingKind tells what kind of problem a Finding is about
+type FindingKind uint8
+
+const (
+	FindingUnknownField      FindingKind = iota // a field in the file that is not part of the DB
+	FindingNonMidnightDate                      // a date with a time of day, or not in UTC
+	FindingDuplicateCustomer                    // customer names differing only by case
+	FindingDuplicateEntry                       // entries for the same date, alike in all but ID
+	FindingEmptyEntry                           // an entry without amount, time worked or comment
+	FindingDuplicateEntryID                     // an ID used by more than one entry of a customer
+)
+
+var findingKindNames = map[FindingKind]string{
+	FindingUnknownField:      "unknown-field",
+	FindingNonMidnightDate:   "non-midnight-date",
+	FindingDuplicateCustomer: "duplicate-customer",
+	FindingDuplicateEntry:    "duplicate-entry",
+	FindingEmptyEntry:        "empty-entry",
+	FindingDuplicateEntryID:  "duplicate-id",
+}
+
+func (kind FindingKind) String() string {
+	if name, ok := findingKindNames[kind]; ok {
+		return name
+	}
+	return fmt.Sprintf("FindingKind(%d)", kind)
+}
+
+// A Finding is a problem with the integrity of a DB, as found by DB.Validate
+type Finding struct {
+	Kind     FindingKind
+	Customer string // the customer concerned, if any
+	EntryID  string // the entry concerned, if any
+	Detail   string
+	Fixed    bool // set by DB.Fix for problems it fixed
+}
+
+func (finding Finding) String() string {
+	parts := []string{finding.Kind.String()}
+	if finding.Customer != "" {
+		parts = append(parts, finding.Customer)
+	}
+	if finding.EntryID != "" {
+		parts = append(parts, "entry "+finding.EntryID)
+	}
+	parts = append(parts, finding.Detail)
+	return strings.Join(parts, ": ")
+}
+
+// UnknownFieldFindings returns a Finding for each of the paths returned by DecodeDBLenient
+func UnknownFieldFindings(paths []string) []Finding {
+	findings := make([]Finding, 0, len(paths))
+	for _, path := range paths {
+		findings = append(findings, Finding{Kind: FindingUnknownField, Detail: path})
+	}
+	return findings
+}
+
+// integrityCheck finds one kind of problem, and fixes it if fix is true
+type integrityCheck func(db *DB, fix bool) []Finding
+
+// integrityChecks are run in order, as fixing some problems can reveal others,
+// like normalized dates making entries duplicates
+var integrityChecks = []struct {
+	kind  FindingKind
+	check integrityCheck
+}{
+	{FindingNonMidnightDate, checkDates},
+	{FindingDuplicateCustomer, checkDuplicateCustomers},
+	{FindingDuplicateEntry, checkDuplicateEntries},
+	{FindingEmptyEntry, checkEmptyEntries},
+	{FindingDuplicateEntryID, checkDuplicateEntryIDs},
+}
+
+// Validate returns the integrity problems of the DB, without changing anything.
+// Several entries for a date are fine, as they can be added on purpose, unless they are alike in all but ID.
+func (db *DB) Validate() []Finding {
+	findings := make([]Finding, 0)
+	for _, integrity := range integrityChecks {
+		findings = append(findings, integrity.check(db, false)...)
+	}
+	return findings
+}
+
+// Fix fixes what it can of the problems Validate finds, and returns them, with Fixed set for those it fixed:
+// dates are cut to midnight UTC, customers with the same name are merged, empty entries are dropped,
+// and entries sharing an ID get new ones.
+// Customers that both have a running session are not merged.
+// Entries alike in all but ID are only dropped if dropDuplicates is true, as they may well be on purpose,
+// like two adjustments of 30 minutes on the same date, and dropping them would lose time.
+func (db *DB) Fix(dropDuplicates bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, integrity := range integrityChecks {
+		fix := integrity.kind != FindingDuplicateEntry || dropDuplicates
+		findings = append(findings, integrity.check(db, fix)...)
+	}
+	return findings
+}
+
+func isMidnightUTC(t time.Time) bool {
+	return t.Equal(DateOf(t)) && t.Location() == time.UTC
+}
+
+func checkDates(db *DB, fix bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, customer := range db.Customers {
+		for _, entry := range customer.Entries {
+			if isMidnightUTC(entry.Date) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind:     FindingNonMidnightDate,
+				Customer: customer.Name,
+				EntryID:  entry.ID,
+				Detail:   fmt.Sprintf("date %s", entry.Date.Format(time.RFC3339)),
+				Fixed:    fix,
+			})
+			if fix {
+				entry.Date = DateOf(entry.Date)
+			}
+		}
+		if customer.StartDate != nil && !isMidnightUTC(*customer.StartDate) {
+			findings = append(findings, Finding{
+				Kind:     FindingNonMidnightDate,
+				Customer: customer.Name,
+				Detail:   fmt.Sprintf("start date %s", customer.StartDate.Format(time.RFC3339)),
+				Fixed:    fix,
+			})
+			if fix {
+				date := DateOf(*customer.StartDate)
+				customer.StartDate = &date
+			}
+		}
+	}
+	for _, day := range db.Calendar {
+		if isMidnightUTC(day.Date) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind:   FindingNonMidnightDate,
+			Detail: fmt.Sprintf("calendar day %s", day.Date.Format(time.RFC3339)),
+			Fixed:  fix,
+		})
+		if fix {
+			day.Date = DateOf(day.Date)
+		}
+	}
+	return findings
+}
+
+func checkDuplicateCustomers(db *DB, fix bool) []Finding {
+	findings := make([]Finding, 0)
+	kept := make(Customers, 0, db.Customers.Len())
+	for _, customer := range db.Customers {
+		var first *Customer
+		for _, other := range kept {
+			if strings.EqualFold(other.Name, customer.Name) {
+				first = other
+				break
+			}
+		}
+		if first == nil {
+			kept = append(kept, customer)
+			continue
+		}
+		finding := Finding{
+			Kind:     FindingDuplicateCustomer,
+			Customer: customer.Name,
+			Detail:   fmt.Sprintf("same name as %s, with %s", first.Name, pluralEntries(customer.Entries.Len())),
+		}
+		switch {
+		case !fix:
+			kept = append(kept, customer)
+		case first.HasSession() && customer.HasSession():
+			finding.Detail += ", not merged as both have a running session"
+			kept = append(kept, customer)
+		default:
+			mergeInto(first, customer)
+			finding.Detail += ", merged"
+			finding.Fixed = true
+		}
+		findings = append(findings, finding)
+	}
+	if fix {
+		db.Customers = kept
+	}
+	return findings
+}
+
+// entryKey is what makes entries alike, apart from their IDs
+type entryKey struct {
+	date    time.Time
+	amount  time.Duration
+	worked  time.Duration
+	comment string
+}
+
+func checkDuplicateEntries(db *DB, fix bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, customer := range db.Customers {
+		seen := make(map[entryKey]*Entry)
+		kept := make(Entries, 0, customer.Entries.Len())
+		for _, entry := range customer.Entries {
+			key := entryKey{DateOf(entry.Date), entry.Amount, entry.Worked, entry.Comment}
+			first, found := seen[key]
+			if !found {
+				seen[key] = entry
+				kept = append(kept, entry)
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind:     FindingDuplicateEntry,
+				Customer: customer.Name,
+				EntryID:  entry.ID,
+				Detail:   fmt.Sprintf("same as entry %s on %s", first.ID, key.date.Format(ShortDateFormat)),
+				Fixed:    fix,
+			})
+			if !fix {
+				kept = append(kept, entry)
+			}
+		}
+		if fix {
+			customer.Entries = kept
+		}
+	}
+	return findings
+}
+
+func checkEmptyEntries(db *DB, fix bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, customer := range db.Customers {
+		kept := make(Entries, 0, customer.Entries.Len())
+		for _, entry := range customer.Entries {
+			if entry.Amount != 0 || entry.Worked != 0 || entry.Comment != "" {
+				kept = append(kept, entry)
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind:     FindingEmptyEntry,
+				Customer: customer.Name,
+				EntryID:  entry.ID,
+				Detail:   fmt.Sprintf("nothing on %s", entry.Date.Format(ShortDateFormat)),
+				Fixed:    fix,
+			})
+			if !fix {
+				kept = append(kept, entry)
+			}
+		}
+		if fix {
+			customer.Entries = kept
+		}
+	}
+	return findings
+}
+
+func checkDuplicateEntryIDs(db *DB, fix bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, customer := range db.Customers {
+		seen := make(map[string]bool)
+		for _, entry := range customer.Entries {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				continue
+			}
+			finding := Finding{
+				Kind:     FindingDuplicateEntryID,
+				Customer: customer.Name,
+				EntryID:  entry.ID,
+				Detail:   fmt.Sprintf("also used by another entry, on %s", entry.Date.Format(ShortDateFormat)),
+				Fixed:    fix,
+			}
+			if fix {
+				entry.ID = customer.newEntryID()
+				seen[entry.ID] = true
+				finding.Detail += ", now " + entry.ID
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func pluralEntries(count int) string {
+	if count == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", count)
+}
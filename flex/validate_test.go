@@ -0,0 +1,144 @@
+package flex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// integrityTestDB returns a DB with one of each problem that Validate finds
+func integrityTestDB() *DB {
+	return &DB{
+		Customers: Customers{
+			{
+				Name: "acme",
+				Entries: Entries{
+					{ID: "a1", Date: time.Date(2022, 1, 3, 13, 30, 0, 0, time.UTC), Amount: time.Hour},
+					{ID: "a2", Date: ymd(2022, 1, 3), Amount: time.Hour},
+					{ID: "a3", Date: ymd(2022, 1, 4)},
+					{ID: "a1", Date: ymd(2022, 1, 5), Amount: -time.Hour, Comment: "dentist"},
+				},
+			},
+			{
+				Name: "ACME",
+				Entries: Entries{
+					{ID: "a4", Date: ymd(2022, 1, 6), Amount: 30 * time.Minute},
+				},
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	db := integrityTestDB()
+	findings := db.Validate()
+
+	kinds := make([]FindingKind, 0, len(findings))
+	for _, finding := range findings {
+		assert.False(t, finding.Fixed, finding)
+		kinds = append(kinds, finding.Kind)
+	}
+	assert.Equal(
+		t,
+		[]FindingKind{
+			FindingNonMidnightDate,
+			FindingDuplicateCustomer,
+			FindingDuplicateEntry,
+			FindingEmptyEntry,
+			FindingDuplicateEntryID,
+		},
+		kinds,
+	)
+	assert.Equal(t, "duplicate-entry: acme: entry a2: same as entry a1 on 2022-01-03", findings[2].String())
+	// nothing is changed
+	assert.Equal(t, integrityTestDB(), db)
+}
+
+func TestFix(t *testing.T) {
+	db := integrityTestDB()
+	findings := db.Fix(true)
+
+	kinds := make([]FindingKind, 0, len(findings))
+	for _, finding := range findings {
+		assert.True(t, finding.Fixed, finding)
+		kinds = append(kinds, finding.Kind)
+	}
+	assert.Equal(
+		t,
+		[]FindingKind{
+			FindingNonMidnightDate,
+			FindingDuplicateCustomer,
+			FindingDuplicateEntry,
+			FindingEmptyEntry,
+			FindingDuplicateEntryID,
+		},
+		kinds,
+	)
+
+	if assert.Equal(t, 1, db.Customers.Len()) {
+		customer := db.Customers[0]
+		assert.Equal(t, 3, customer.Entries.Len())
+		assert.Equal(t, ymd(2022, 1, 3), customer.Entries[0].Date)
+		assert.Equal(t, 30*time.Minute, customer.GetTotalFlex())
+	}
+	assert.Empty(t, db.Validate())
+}
+
+func TestFixKeepsDuplicateEntries(t *testing.T) {
+	db := integrityTestDB()
+	for _, finding := range db.Fix(false) {
+		assert.Equal(t, finding.Kind != FindingDuplicateEntry, finding.Fixed, finding)
+	}
+	if assert.Equal(t, 1, db.Customers.Len()) {
+		// only the empty entry is dropped, the two of an hour on 2022-01-03 are both kept
+		assert.Equal(t, 4, db.Customers[0].Entries.Len())
+		assert.Equal(t, 90*time.Minute, db.Customers[0].GetTotalFlex())
+	}
+}
+
+func TestFixKeepsCustomersWithSessions(t *testing.T) {
+	db := integrityTestDB()
+	db.Customers[0].Session = &Session{Start: time.Now()}
+	db.Customers[1].Session = &Session{Start: time.Now()}
+
+	for _, finding := range db.Fix(true) {
+		if finding.Kind == FindingDuplicateCustomer {
+			assert.False(t, finding.Fixed)
+		}
+	}
+	assert.Equal(t, 2, db.Customers.Len())
+}
+
+func TestFixUndo(t *testing.T) {
+	db := integrityTestDB()
+	before, err := db.Clone()
+	assert.NoError(t, err)
+	db.Fix(true)
+	changes, err := DiffDB(before, db)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, changes)
+
+	// Reverting what was fixed gives back the customers as they were, duplicates and all
+	assert.NoError(t, ApplyChanges(db, InvertChanges(changes), false))
+	changes, err = DiffDB(before, db)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, db.Validate(), 5)
+}
+
+func TestDecodeDBUnknownFields(t *testing.T) {
+	input := `{"schema_version":1,"customers":[{"customer_name":"acme","flex_entries":[{"date":"2022-01-03T00:00:00Z","amout":1}],"Notes":"ok"}],"colour":"red"}`
+
+	_, err := DecodeDB(strings.NewReader(input))
+	if assert.ErrorIs(t, err, ErrUnknownField) {
+		assert.Contains(t, err.Error(), "customers[0].flex_entries[0].amout")
+	}
+
+	db, unknown, err := DecodeDBLenient(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colour", "customers[0].flex_entries[0].amout"}, unknown)
+	assert.Equal(t, "ok", db.Customers[0].Notes)
+	assert.Equal(t, FindingUnknownField, UnknownFieldFindings(unknown)[0].Kind)
+}
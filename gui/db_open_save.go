@@ -2,12 +2,28 @@ package main
 
 import (
 	"errors"
-	"fmt"
+	"time"
 
 	"github.com/oddlid/flextime/flex"
+	"github.com/rs/zerolog/log"
 )
 
+// backupCount is how many backup copies of the DB file saveDB keeps
+var backupCount = flex.DefaultBackupCount
+
+// standardWorkday is the configured workday length, given to each opened DB
+var standardWorkday time.Duration
+
+// openDB reads the DB from the given file, see readDB, and applies the configured workday length
 func openDB(fileName string) (*flex.DB, error) {
+	db, err := readDB(fileName)
+	if db != nil {
+		db.Workday = standardWorkday
+	}
+	return db, err
+}
+
+func readDB(fileName string) (*flex.DB, error) {
 	if fileName == "" {
 		db := flex.NewDB()
 		db.FileName = "-"
@@ -38,21 +54,56 @@ func openDB(fileName string) (*flex.DB, error) {
 }
 
 func saveDB(db *flex.DB) error {
-	if db == nil {
-		return fmt.Errorf("refusing to save nil DB")
+	return flex.SaveDB(db, backupCount)
+}
+
+// lockTimeout is how long updateDB waits for another process to release the DB file
+var lockTimeout = flex.DefaultLockTimeout
+
+// updateDB reloads the DB from fileName while holding the lock, applies the given change and saves it,
+// so that changes made by other processes since the GUI loaded the file are not lost.
+// The change is recorded in the journal with the given command description, so it can be undone from the CLI.
+func updateDB(fileName, command string, change func(*flex.DB) error) (*flex.DB, error) {
+	lock, err := flex.AcquireLock(fileName, lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := lock.Release(); err != nil {
+			log.Error().Err(err).Msg("Failed to release lock")
+		}
+	}()
+
+	db, err := openDB(fileName)
+	if err != nil {
+		if db == nil {
+			return nil, err
+		}
+		log.Error().Err(err).Send()
 	}
-	file, err := flex.GetFileOrStdoutForWriting(db.FileName)
+	before, err := db.Clone()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err = change(db); err != nil {
+		return nil, err
 	}
-	err = flex.EncodeDB(db, file)
+	if err = saveDB(db); err != nil {
+		return nil, err
+	}
+
+	changes, err := flex.DiffDB(before, db)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if db.FileName != "-" {
-		if err = file.Close(); err != nil {
-			return err
+	if len(changes) > 0 {
+		journal, err := flex.OpenJournal(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = journal.Append(flex.JournalChange, command, 0, changes); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	return db, nil
 }
@@ -9,8 +9,12 @@ But at least I'll maybe learn something in the process.
 */
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -25,12 +29,75 @@ import (
 var _db *flex.DB
 var _currentCustomer *flex.Customer
 
+// _config holds the defaults from the same config file as the CLI uses
+var _config = &flex.Config{}
+
+// durationFormat is how the flex total is shown, set with $FLEXTIME_DURATION_FORMAT or in the config
+var durationFormat = flex.DurationFormatGo
+
+// loadConfig loads the config file, and applies its defaults.
+// The environment variables checked in init override these.
+func loadConfig() {
+	fileName, err := flex.ConfigFileName()
+	if err != nil {
+		log.Debug().Err(err).Msg("No config file")
+		return
+	}
+	config, err := flex.LoadConfig(fileName)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid config, using defaults")
+		return
+	}
+	_config = config
+
+	if config.Backups != nil {
+		backupCount = *config.Backups
+	}
+	if config.DurationFormat != "" {
+		// already validated by LoadConfig
+		durationFormat, _ = flex.ParseDurationFormat(config.DurationFormat)
+	}
+	standardWorkday, _ = config.WorkdayLength()
+}
+
 func init() {
 	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.999-07:00"
 
+	loadConfig()
+
+	if backups, present := os.LookupEnv("FLEXTIME_BACKUPS"); present {
+		count, err := strconv.Atoi(backups)
+		if err != nil {
+			log.Error().Err(err).Str("FLEXTIME_BACKUPS", backups).Msg("Invalid backup count, using default")
+		} else {
+			backupCount = count
+		}
+	}
+
+	if timeout, present := os.LookupEnv("FLEXTIME_LOCK_TIMEOUT"); present {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Error().Err(err).Str("FLEXTIME_LOCK_TIMEOUT", timeout).Msg("Invalid lock timeout, using default")
+		} else {
+			lockTimeout = duration
+		}
+	}
+
+	if name, present := os.LookupEnv("FLEXTIME_DURATION_FORMAT"); present {
+		format, err := flex.ParseDurationFormat(name)
+		if err != nil {
+			log.Error().Err(err).Str("FLEXTIME_DURATION_FORMAT", name).Msg("Invalid duration format, using default")
+		} else {
+			durationFormat = format
+		}
+	}
+
 	dbfile, present := os.LookupEnv("FLEXTIME_FILE")
 	if !present {
-		log.Debug().Msg("$FLEXTIME_FILE not set, not attempting load")
+		dbfile = _config.File
+	}
+	if dbfile == "" {
+		log.Debug().Msg("$FLEXTIME_FILE not set and no file in config, not attempting load")
 		return
 	}
 	log.Debug().Str("FLEXTIME_FILE", dbfile).Msg("DB file was specified")
@@ -47,6 +114,7 @@ type addEntryRowWidget struct {
 	txtDateBinding    binding.String
 	txtAmountBinding  binding.String
 	txtCommentBinding binding.String
+	errorBinding      binding.String // what went wrong with the last add, if anything
 	btnAdd            *widget.Button
 	layoutContainer   *fyne.Container
 }
@@ -70,6 +138,7 @@ func getAddEntryRowWidget(btnAddFunc func()) *addEntryRowWidget {
 		txtDateBinding:    binding.NewString(),
 		txtAmountBinding:  binding.NewString(),
 		txtCommentBinding: binding.NewString(),
+		errorBinding:      binding.NewString(),
 	}
 	lblDate := widget.NewLabel("Date:")
 	lblAmount := widget.NewLabel("Amount:")
@@ -77,9 +146,11 @@ func getAddEntryRowWidget(btnAddFunc func()) *addEntryRowWidget {
 	txtDate := widget.NewEntryWithData(w.txtDateBinding)
 	txtAmount := widget.NewEntryWithData(w.txtAmountBinding)
 	txtComment := widget.NewEntryWithData(w.txtCommentBinding)
+	lblError := widget.NewLabelWithData(w.errorBinding)
 	layoutContainer := container.NewBorder(
-		nil, nil, nil, w.btnAdd,
+		nil, lblError, nil, w.btnAdd,
 		w.btnAdd,
+		lblError,
 		container.NewGridWithColumns(
 			3,
 			container.NewBorder(
@@ -104,6 +175,55 @@ func getAddEntryRowWidget(btnAddFunc func()) *addEntryRowWidget {
 	return w
 }
 
+// entry returns the entry filled in on the row, for today unless a date is given
+func (aerw *addEntryRowWidget) entry(now time.Time) (flex.Entry, error) {
+	dateText, _ := aerw.txtDateBinding.Get()
+	amountText, _ := aerw.txtAmountBinding.Get()
+	comment, _ := aerw.txtCommentBinding.Get()
+
+	date := flex.DateOf(now)
+	if strings.TrimSpace(dateText) != "" {
+		parsed, err := flex.ParseDate(dateText, now)
+		if err != nil {
+			return flex.Entry{}, err
+		}
+		date = parsed
+	}
+	amount, err := flex.ParseFlexDuration(amountText)
+	if err != nil {
+		return flex.Entry{}, err
+	}
+	if amount == 0 {
+		return flex.Entry{}, fmt.Errorf("%w: amount must not be zero", flex.ErrInvalidDuration)
+	}
+	return flex.Entry{Date: date, Amount: amount, Comment: strings.TrimSpace(comment)}, nil
+}
+
+// clear empties the amount, comment and error, keeping the date for adding more on the same day
+func (aerw *addEntryRowWidget) clear() {
+	aerw.txtAmountBinding.Set("")
+	aerw.txtCommentBinding.Set("")
+	aerw.errorBinding.Set("")
+}
+
+// showError shows what went wrong in the row, and logs it
+func (aerw *addEntryRowWidget) showError(err error) {
+	log.Error().Err(err).Send()
+	aerw.errorBinding.Set(err.Error())
+}
+
+// addEntry adds the entry for the named customer to the DB file, under its lock, see updateDB,
+// and returns the DB as saved
+func addEntry(fileName, customerName string, entry flex.Entry) (*flex.DB, error) {
+	if fileName == "" || fileName == "-" {
+		return nil, errors.New("no DB file to save to, set $FLEXTIME_FILE or file in the config")
+	}
+	return updateDB(fileName, "gui add -c "+customerName, func(db *flex.DB) error {
+		db.AddEntryForCustomer(customerName, entry)
+		return nil
+	})
+}
+
 func longestEntry(slice []string) int {
 	maxLen := 0
 	for _, entry := range slice {
@@ -167,7 +287,15 @@ func getCustomerHeaderWidget() *customerHeaderWidget {
 
 func (chw *customerHeaderWidget) sync(c *flex.Customer) {
 	chw.nameBinding.Set(c.Name)
-	chw.flexTotalBinding.Set(c.GetTotalFlex().String())
+	workday := flex.DefaultWorkdayLength
+	if _db != nil {
+		workday = _db.ScheduleFor(c).Workday()
+	}
+	total := durationFormat.Format(c.GetTotalFlex(), workday)
+	if durationFormat == flex.DurationFormatDecimal && _config.DecimalComma() {
+		total = strings.ReplaceAll(total, ".", ",")
+	}
+	chw.flexTotalBinding.Set(total)
 }
 
 func getWindowContainer(
@@ -189,16 +317,35 @@ func main() {
 	a := app.New()
 	w := a.NewWindow("FlexTime GUI test")
 
+	clw := getCustomerListWidget()
+	chw := getCustomerHeaderWidget()
+	var aerw *addEntryRowWidget
 	btnAddFunc := func() {
-		if _currentCustomer != nil {
-			log.Debug().Str("customer", _currentCustomer.Name).Msg("Add to this customer")
+		if _db == nil || _currentCustomer == nil {
+			aerw.showError(errors.New("select a customer to add to"))
+			return
+		}
+		entry, err := aerw.entry(time.Now())
+		if err != nil {
+			aerw.showError(err)
 			return
 		}
-		log.Debug().Msg("Add button clicked")
+		customerName := _currentCustomer.Name
+		db, err := addEntry(_db.FileName, customerName, entry)
+		if err != nil {
+			aerw.showError(err)
+			return
+		}
+		log.Debug().Str("customer", customerName).Msg("Added entry")
+		_db = db
+		clw.sync(_db.Customers)
+		if customer, err := _db.GetCustomer(customerName); err == nil {
+			_currentCustomer = customer
+			chw.sync(customer)
+		}
+		aerw.clear()
 	}
-	clw := getCustomerListWidget()
-	chw := getCustomerHeaderWidget()
-	aerw := getAddEntryRowWidget(btnAddFunc)
+	aerw = getAddEntryRowWidget(btnAddFunc)
 	if _db != nil {
 		clw.sync(_db.Customers)
 		clw.list.OnSelected = func(id widget.ListItemID) {
@@ -211,6 +358,12 @@ func main() {
 			chw.sync(customer)
 			_currentCustomer = customer
 		}
+		for idx, name := range clw.customerNames {
+			if _config.Customer != "" && strings.EqualFold(name, _config.Customer) {
+				clw.list.Select(idx)
+				break
+			}
+		}
 	}
 	w.SetContent(
 		getWindowContainer(clw, chw, aerw),